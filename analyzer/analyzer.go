@@ -0,0 +1,193 @@
+// Package analyzer reports njson struct tags that would fail or silently
+// decode nothing at runtime: malformed paths, unknown options, `#` queries
+// on scalar fields and field types njson can't decode into. It checks tags
+// with the same rules as njson.Validate, statically
+//
+// Transforms registered at runtime with njson.RegisterTransform are unknown
+// to it, their names are passed with the -transforms flag
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/m7shapan/njson"
+	"github.com/m7shapan/njson/internal/astutil"
+	"github.com/tidwall/gjson"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer checks the njson and njsonpath tags of struct fields
+var Analyzer = &analysis.Analyzer{
+	Name:     "njson",
+	Doc:      "check njson struct tags for invalid paths, options and field types",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func init() {
+	Analyzer.Flags.Var(transformNames{}, "transforms", "comma-separated names of transforms registered at runtime, accepted as tag options")
+}
+
+// transformNames registers the names it is set to as transforms that keep
+// values as they are, so that tags using them check as they would at runtime
+type transformNames struct{}
+
+func (transformNames) String() string { return "" }
+
+func (transformNames) Set(names string) (err error) {
+	// RegisterTransform panics on reserved names
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			njson.RegisterTransform(name, keep)
+		}
+	}
+
+	return nil
+}
+
+func keep(value gjson.Result, _ string) (gjson.Result, error) {
+	return value, nil
+}
+
+// options checks njson tags even where a json tag would take precedence
+var options = njson.UnmarshalOptions{JSONTag: njson.JSONTagIgnored}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// knownTypes are the types njson decodes specially, by their full name
+var knownTypes = map[string]reflect.Type{
+	"time.Time":      reflect.TypeOf(time.Time{}),
+	"math/big.Int":   reflect.TypeOf(big.Int{}),
+	"math/big.Float": reflect.TypeOf(big.Float{}),
+	"math/big.Rat":   reflect.TypeOf(big.Rat{}),
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		for _, field := range n.(*ast.StructType).Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+
+			typ := reflectType(pass.TypesInfo.TypeOf(field.Type), map[types.Type]bool{})
+			for _, name := range astutil.FieldNames(field) {
+				sf := reflect.StructField{Name: name, Type: typ, Tag: reflect.StructTag(tag)}
+				if err := options.ValidateField(sf); err != nil {
+					pass.Reportf(field.Tag.Pos(), "%v", err)
+				}
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+// reflectType builds a reflect.Type with the shape of typ, which is all
+// njson's checks look at. Named types that decode themselves become
+// interface{}, which no check rejects, and structs become empty structs as
+// their own fields are checked where they are declared
+func reflectType(typ types.Type, seen map[types.Type]bool) reflect.Type {
+	if typ == nil || seen[typ] {
+		return interfaceType
+	}
+
+	switch t := typ.(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil {
+			if known, ok := knownTypes[obj.Pkg().Path()+"."+obj.Name()]; ok {
+				return known
+			}
+		}
+
+		if decodesItself(t) {
+			return interfaceType
+		}
+
+		seen[typ] = true
+		defer delete(seen, typ)
+		return reflectType(t.Underlying(), seen)
+	case *types.Basic:
+		if b, ok := basicTypes[t.Kind()]; ok {
+			return b
+		}
+		return interfaceType
+	case *types.Pointer:
+		return reflect.PtrTo(reflectType(t.Elem(), seen))
+	case *types.Slice:
+		return reflect.SliceOf(reflectType(t.Elem(), seen))
+	case *types.Array:
+		return reflect.ArrayOf(int(t.Len()), reflectType(t.Elem(), seen))
+	case *types.Map:
+		key := reflectType(t.Key(), seen)
+		if !key.Comparable() {
+			key = interfaceType
+		}
+		return reflect.MapOf(key, reflectType(t.Elem(), seen))
+	case *types.Chan:
+		return reflect.ChanOf(reflect.BothDir, reflectType(t.Elem(), seen))
+	case *types.Signature:
+		return reflect.TypeOf(func() {})
+	case *types.Struct:
+		return reflect.TypeOf(struct{}{})
+	default:
+		return interfaceType
+	}
+}
+
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Bool:          reflect.TypeOf(false),
+	types.Int:           reflect.TypeOf(int(0)),
+	types.Int8:          reflect.TypeOf(int8(0)),
+	types.Int16:         reflect.TypeOf(int16(0)),
+	types.Int32:         reflect.TypeOf(int32(0)),
+	types.Int64:         reflect.TypeOf(int64(0)),
+	types.Uint:          reflect.TypeOf(uint(0)),
+	types.Uint8:         reflect.TypeOf(uint8(0)),
+	types.Uint16:        reflect.TypeOf(uint16(0)),
+	types.Uint32:        reflect.TypeOf(uint32(0)),
+	types.Uint64:        reflect.TypeOf(uint64(0)),
+	types.Uintptr:       reflect.TypeOf(uintptr(0)),
+	types.Float32:       reflect.TypeOf(float32(0)),
+	types.Float64:       reflect.TypeOf(float64(0)),
+	types.Complex64:     reflect.TypeOf(complex64(0)),
+	types.Complex128:    reflect.TypeOf(complex128(0)),
+	types.String:        reflect.TypeOf(""),
+	types.UnsafePointer: reflect.TypeOf(unsafe.Pointer(nil)),
+}
+
+// decodesItself reports whether a pointer to typ has one of the methods
+// njson decodes values with instead of using their kind
+func decodesItself(typ *types.Named) bool {
+	methods := types.NewMethodSet(types.NewPointer(typ))
+	for _, name := range []string{"UnmarshalJSON", "Scan"} {
+		if methods.Lookup(nil, name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzerTransforms(t *testing.T) {
+	if err := Analyzer.Flags.Set("transforms", "slugify"); err != nil {
+		t.Fatal(err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "b")
+
+	if err := Analyzer.Flags.Set("transforms", "min"); err == nil {
+		t.Error("expected an error for a reserved name")
+	}
+}
@@ -0,0 +1,14 @@
+// Command njsonvet checks the njson struct tags of Go packages
+//
+//	go vet -vettool=$(which njsonvet) ./...
+//	njsonvet ./...
+package main
+
+import (
+	"github.com/m7shapan/njson/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,16 @@
+module github.com/m7shapan/njson/analyzer
+
+go 1.22.0
+
+require (
+	github.com/m7shapan/njson v1.1.0
+	github.com/tidwall/gjson v1.12.1
+	golang.org/x/tools v0.26.0
+)
+
+require (
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.0 // indirect
+	golang.org/x/mod v0.21.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+)
@@ -0,0 +1,35 @@
+package a
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Friend struct {
+	First string `njson:"first"`
+	Age   int    `njson:"age,min=x"` // want `field Age \(path "age"\): invalid rule min=x`
+}
+
+type Amount struct{}
+
+func (a *Amount) UnmarshalJSON(data []byte) error { return nil }
+
+type User struct {
+	Name     string          `njson:"name.first,trim,lower"`
+	Count    int             `njson:"friends.#"`
+	Ages     int             `njson:"friends.#.age"` // want `path "friends.#.age" gives an array, which can't be decoded into int`
+	Names    []string        `njson:"friends.#.first"`
+	First    string          `njson:"friends.#.first"`  // want `path "friends.#.first" gives an array, which can't be decoded into string`
+	Bad      string          `njson:"friends.#(age>40"` // want `unclosed '\('`
+	Unknown  string          `njson:"name.last,snake"`  // want `unknown option "snake"`
+	Pointer  string          `njson:"/items/~2"`        // want `invalid json pointer`
+	Query    []string        `njsonpath:"$..author"`
+	Done     chan bool       `njson:"done"` // want `can't decode into chan bool`
+	Friends  []Friend        `njson:"friends"`
+	Created  time.Time       `njson:"created"`
+	Amounts  Amount          `njson:"friends.#.amount"`
+	Raw      json.RawMessage `njson:"raw"`
+	Plain    string          `json:"plain,string"`
+	Both     string          `json:"both" njson:"both."` // want `empty component`
+	internal string          `njson:"internal."`
+}
@@ -0,0 +1,8 @@
+package b
+
+type User struct {
+	Slug   string `njson:"name,slugify"`
+	Tags   string `njson:"tags,slugify,trim"`
+	Typo   string `njson:"name,slugfy"` // want `unknown option "slugfy"`
+	Shaped int    `njson:"friends.#.age,slugify"`
+}
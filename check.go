@@ -0,0 +1,400 @@
+package njson
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+	"github.com/tidwall/gjson"
+)
+
+// Validate checks the tags of the struct type typ, and of the structs it
+// contains, without decoding anything: path syntax, tag options and whether
+// the shape of each path suits its field, e.g. `njson:"friends.#.name"` on a
+// string field. Every problem is reported, as Errors
+func Validate(typ reflect.Type) error {
+	return UnmarshalOptions{}.Validate(typ)
+}
+
+// Validate is like the package level Validate, using the tags configured by o
+func (o UnmarshalOptions) Validate(typ reflect.Type) error {
+	c := &typeChecker{opts: o, seen: map[reflect.Type]bool{}}
+	if err := c.checkStruct(typ, fieldInfo{}); err != nil {
+		return err
+	}
+
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+
+	return nil
+}
+
+// ValidateSample is like Validate, and also checks that every path of typ
+// exists in the sample document
+func ValidateSample(typ reflect.Type, sample []byte) error {
+	return UnmarshalOptions{}.ValidateSample(typ, sample)
+}
+
+// ValidateSample is like the package level ValidateSample, using the tags
+// configured by o
+func (o UnmarshalOptions) ValidateSample(typ reflect.Type, sample []byte) error {
+	if !gjson.ValidBytes(sample) {
+		return fmt.Errorf("invalid json: %v", string(sample))
+	}
+
+	c := &typeChecker{opts: o, seen: map[reflect.Type]bool{}, sample: true}
+	c.doc = gjson.ParseBytes(sample)
+	if err := c.checkStruct(typ, fieldInfo{}); err != nil {
+		return err
+	}
+
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+
+	return nil
+}
+
+// MustRegister validates the type of v, a struct or a pointer to one, and
+// panics if its tags have any problem. It is meant to be called from init
+// or tests so that mistakes surface before the first decode
+//
+//	func init() {
+//		njson.MustRegister(User{})
+//	}
+func MustRegister(v interface{}) {
+	if err := Validate(reflect.TypeOf(v)); err != nil {
+		panic(err)
+	}
+}
+
+// ValidatePath reports syntax errors in a njson tag path, be it a gjson
+// path, a JSON Pointer or a JSONPath query
+func ValidatePath(path string) error {
+	_, err := checkTagPath(path, nil)
+	return err
+}
+
+// ValidateField checks the tag of a single struct field as Validate does,
+// without looking into the structs it holds, and returns nil for fields
+// njson doesn't decode. It serves tools that only know a type statically,
+// such as the njson/analyzer package
+func ValidateField(sf reflect.StructField) error {
+	return UnmarshalOptions{}.ValidateField(sf)
+}
+
+// ValidateField is like the package level ValidateField, using the tags
+// configured by o
+func (o UnmarshalOptions) ValidateField(sf reflect.StructField) error {
+	tag, opts, ok := o.fieldTag(sf)
+	if !ok {
+		return nil
+	}
+
+	if _, err := checkField(sf.Type, tag, opts); err != nil {
+		return &FieldError{Field: sf.Name, Path: tag, Err: err}
+	}
+
+	return nil
+}
+
+// checkField validates the tag of a field of type typ and returns the path
+// it is decoded from, or "" if the path itself is invalid
+func checkField(typ reflect.Type, tag string, opts tagOptions) (string, error) {
+	path, err := checkTagPath(tag, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkKind(typ); err != nil {
+		return path, err
+	}
+
+	if err := checkOptions(typ, opts); err != nil {
+		return path, err
+	}
+
+	return path, checkShape(typ, path, opts)
+}
+
+// checkTagPath validates path and returns it as decoding will use it
+func checkTagPath(path string, opts tagOptions) (string, error) {
+	switch {
+	case isPointer(path, opts):
+		return pointerPath(path)
+	case tagpath.IsJSONPath(path):
+		_, err := compileJSONPath(path)
+		return path, err
+	default:
+		return path, checkPath(path)
+	}
+}
+
+type typeChecker struct {
+	opts UnmarshalOptions
+	seen map[reflect.Type]bool
+	errs Errors
+
+	// sample checks paths against doc
+	sample bool
+	doc    gjson.Result
+}
+
+func (c *typeChecker) fail(f fieldInfo, err error) {
+	c.errs = append(c.errs, &FieldError{Field: f.name(), Path: f.path(), Err: err})
+}
+
+func (c *typeChecker) checkStruct(typ reflect.Type, parent fieldInfo) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("can't validate invalid type %v", typ)
+	}
+
+	// a recursive type is checked once, unless checking against a sample
+	// where each occurrence has a document of its own
+	if c.seen[typ] && !c.sample {
+		return nil
+	}
+	c.seen[typ] = true
+	doc := c.doc
+
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+
+		tag, opts, ok := c.opts.fieldTag(sf)
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+
+		f := parent.child(sf.Name, tag, opts)
+
+		path, err := checkField(sf.Type, tag, opts)
+		if err != nil {
+			c.fail(f, err)
+		}
+		if path == "" {
+			continue
+		}
+
+		if c.sample {
+			d := &decodeState{opts: c.opts}
+			result, err := d.lookup([]byte(doc.Raw), path)
+			// an array query matching no element gives an empty array
+			empty := shapeOf(path) == shapeArray && len(result.Array()) == 0
+			if err != nil || !result.Exists() || empty {
+				c.fail(f, fmt.Errorf("path not found in sample"))
+				continue
+			}
+			c.doc = result
+		}
+
+		c.checkNested(sf.Type, f)
+		c.doc = doc
+	}
+
+	return nil
+}
+
+// checkNested checks the structs njson decodes inside a field of type typ
+func (c *typeChecker) checkNested(typ reflect.Type, f fieldInfo) {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		c.checkNested(typ.Elem(), f)
+	case reflect.Struct:
+		if typ == timeType || decodesItself(typ) {
+			return
+		}
+		_ = c.checkStruct(typ, f)
+	case reflect.Slice, reflect.Array:
+		if c.sample {
+			if !c.doc.IsArray() || len(c.doc.Array()) == 0 {
+				return
+			}
+			c.doc = c.doc.Array()[0]
+		}
+		c.checkNested(typ.Elem(), f.elem(0))
+	case reflect.Map:
+		if c.sample {
+			var first gjson.Result
+			c.doc.ForEach(func(_, value gjson.Result) bool {
+				first = value
+				return false
+			})
+			if !first.Exists() {
+				return
+			}
+			c.doc = first
+		}
+		c.checkNested(typ.Elem(), f.key("*"))
+	}
+}
+
+// decodesItself reports whether values of typ are decoded by their own
+// methods or a registered decoder rather than field by field
+func decodesItself(typ reflect.Type) bool {
+	if registeredEnum(typ) != nil || numberParser(typ) != nil {
+		return true
+	}
+
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(reflect.TypeOf((*fieldDecoder)(nil)).Elem()) ||
+		ptr.Implements(reflect.TypeOf((*interface{ UnmarshalJSON([]byte) error })(nil)).Elem()) && !hasNJSONTags(typ) ||
+		ptr.Implements(reflect.TypeOf((*interface{ Scan(interface{}) error })(nil)).Elem())
+}
+
+// hasNJSONTags reports whether the struct typ has fields with njson tags.
+// Such structs are decoded from their tags even if they have an
+// UnmarshalJSON method, which is left to encoding/json
+func hasNJSONTags(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if validTag(sf, njsonTag) || validTag(sf, njsonPathTag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodesFields reports whether values of typ are decoded from the paths of
+// their own tags, rather than from the whole value
+func decodesFields(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeType && !decodesItself(typ)
+}
+
+// checkKind reports field types njson has no way to decode into
+func checkKind(typ reflect.Type) error {
+	switch scalarElem(typ).Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return fmt.Errorf("can't decode into %v", typ)
+	}
+
+	return nil
+}
+
+// checkOptions reports unknown tag options and option values that can't
+// work with a field of type typ
+func checkOptions(typ reflect.Type, opts tagOptions) error {
+	elem := scalarElem(typ)
+
+	for _, opt := range opts {
+		switch opt.name {
+		case "elements":
+			switch opt.value {
+			case "fail", "skip", "zero":
+			default:
+				return fmt.Errorf("unknown elements policy: %s", opt.value)
+			}
+		case "pointer":
+		case "string":
+			if !isNumberKind(elem.Kind()) && elem.Kind() != reflect.Bool && elem.Kind() != reflect.String {
+				return fmt.Errorf("invalid use of ,string with %v", typ)
+			}
+		case "enum":
+			if opt.value == "" {
+				return fmt.Errorf("enum option needs values, e.g. enum=a|b")
+			}
+			// enums decode into strings and integers, not floats
+			isFloat := elem.Kind() == reflect.Float32 || elem.Kind() == reflect.Float64
+			if isFloat || !isNumberKind(elem.Kind()) && elem.Kind() != reflect.String {
+				return fmt.Errorf("enum option can't be used with %v", typ)
+			}
+		case "min", "max":
+			if _, err := strconv.ParseFloat(opt.value, 64); err != nil {
+				return fmt.Errorf("invalid rule %s=%s", opt.name, opt.value)
+			}
+		case "len":
+			if _, err := strconv.Atoi(opt.value); err != nil {
+				return fmt.Errorf("invalid rule len=%s", opt.value)
+			}
+		case "regex":
+			if _, err := compileRegexp(opt.value); err != nil {
+				return err
+			}
+		case "oneof":
+			if opt.value == "" {
+				return fmt.Errorf("oneof rule needs values, e.g. oneof=a|b")
+			}
+		case "required", "nonempty", "email":
+		default:
+			if transform(opt.name) == nil {
+				return fmt.Errorf("unknown option %q", opt.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkShape reports a path whose shape can't fill a field of type typ,
+// such as an array of values for an int or a string
+func checkShape(typ reflect.Type, path string, opts tagOptions) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	scalar := isNumberKind(typ.Kind()) || typ.Kind() == reflect.Bool || typ.Kind() == reflect.String
+	if !scalar || decodesItself(typ) || reshapes(opts) {
+		return nil
+	}
+
+	switch shapeOf(path) {
+	case shapeArray:
+		return fmt.Errorf("path %q gives an array, which can't be decoded into %v", path, typ)
+	case shapeCount:
+		if typ.Kind() == reflect.Bool {
+			return fmt.Errorf("path %q gives a count, which can't be decoded into %v", path, typ)
+		}
+	}
+
+	return nil
+}
+
+// reshapes reports whether opts hold a transform that may change the shape
+// of a value: split, or one registered by the user
+func reshapes(opts tagOptions) bool {
+	for _, opt := range opts {
+		switch opt.name {
+		case "trim", "lower", "upper":
+		default:
+			if transform(opt.name) != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// scalarElem returns the type options apply to: the element type of
+// slices, arrays and maps, as options are passed down to them
+func scalarElem(typ reflect.Type) reflect.Type {
+	for {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		default:
+			return typ
+		}
+	}
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
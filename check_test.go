@@ -0,0 +1,200 @@
+package njson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidatePath(t *testing.T) {
+	tests := []struct {
+		path string
+		err  bool
+	}{
+		{path: "name.first"},
+		{path: "friends.#.name"},
+		{path: `friends.#(last=="Murphy")#.first`},
+		{path: `fav\.movie`},
+		{path: "children|@reverse|0"},
+		{path: "/items/0/name"},
+		{path: "$.store.book[?@.price < 10].title"},
+		{path: "", err: true},
+		{path: "name.", err: true},
+		{path: `name\`, err: true},
+		{path: `friends.#(last=="Murphy"#.first`, err: true},
+		{path: `friends.#(last=="Murphy)#`, err: true},
+		{path: "friends.#first", err: true},
+		{path: "children|@nope", err: true},
+		{path: "/a~2", err: true},
+		{path: "$.store[", err: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePath(tt.path)
+		if tt.err != (err != nil) {
+			t.Errorf("%q: unexpected error %v", tt.path, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type Friend struct {
+		Name string `njson:"first"`
+		Age  int    `njson:"age,min=x"`
+	}
+
+	type User struct {
+		Name     string            `njson:"name.first"`
+		Count    int               `njson:"friends.#"`
+		Ages     int               `njson:"friends.#.age"`
+		Status   string            `njson:"status,enum"`
+		Rate     []float64         `njson:"rates,enum=1|2"`
+		Policy   []int             `njson:"ids,elements=ignore"`
+		Unknown  string            `njson:"name.last,snake"`
+		Bad      string            `njson:"friends.#(age>40"`
+		Friends  []Friend          `njson:"friends"`
+		Nested   map[string]Friend `njson:"by_name"`
+		Quoted   int               `njson:"count,string"`
+		Tags     []string          `njson:"friends.#.name"`
+		Names    string            `njson:"friends.#.name"`
+		Split    []string          `njson:"tags,split=,"`
+		Joined   string            `njson:"friends.#.name,trim"`
+		Zone     *Friend           `njson:"zone"`
+		Done     chan bool         `njson:"done"`
+		Internal string
+	}
+
+	err := Validate(reflect.TypeOf(User{}))
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var actual []string
+	for _, err := range errs {
+		actual = append(actual, err.Field)
+	}
+
+	expected := []string{
+		"Ages",
+		"Status",
+		"Rate",
+		"Policy",
+		"Unknown",
+		"Bad",
+		// each struct type is checked once
+		"Friends[0].Age",
+		"Names",
+		"Joined",
+		"Done",
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("(-expected, +actual): %s; %v", diff, err)
+	}
+
+	type Valid struct {
+		Name    string      `njson:"name.first,trim,nonempty"`
+		Friends []string    `njson:"friends.#.first"`
+		Count   int         `njson:"friends.#"`
+		Total   string      `njson:"friends.#"`
+		Raw     json.Number `njson:"age"`
+		Age     int         `njson:"/age,min=0,max=150"`
+		Self    *Valid      `njson:"self"`
+	}
+
+	if err := Validate(reflect.TypeOf(&Valid{})); err != nil {
+		t.Error(err)
+	}
+
+	if err := Validate(reflect.TypeOf("")); err == nil {
+		t.Error("error should not be nil for a non struct type")
+	}
+}
+
+func TestValidateField(t *testing.T) {
+	type User struct {
+		Name  string `njson:"name.first"`
+		Ages  int    `njson:"friends.#.age"`
+		Plain string
+	}
+
+	typ := reflect.TypeOf(User{})
+
+	if err := ValidateField(typ.Field(0)); err != nil {
+		t.Error(err)
+	}
+
+	var fe *FieldError
+	if err := ValidateField(typ.Field(1)); !errors.As(err, &fe) || fe.Field != "Ages" {
+		t.Errorf("error should be a FieldError for Ages, got %v", err)
+	}
+
+	if err := ValidateField(typ.Field(2)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateSample(t *testing.T) {
+	json := `
+	{
+		"name": {"first": "Tom", "last": "Anderson"},
+		"friends": [
+			{"first": "Dale", "last": "Murphy", "age": 44},
+			{"first": "Roger", "last": "Craig", "age": 68}
+		]
+	}`
+
+	type Friend struct {
+		First string `njson:"first"`
+		Last  string `njson:"lats"`
+	}
+
+	type User struct {
+		Name    string   `njson:"name.first"`
+		Names   []string `njson:"friends.#.nmae"`
+		Friends []Friend `njson:"friends"`
+		Age     int      `njson:"$.friends[0].age"`
+	}
+
+	err := ValidateSample(reflect.TypeOf(User{}), []byte(json))
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var actual []string
+	for _, err := range errs {
+		actual = append(actual, err.Path)
+	}
+
+	expected := []string{"friends.#.nmae", "friends.0.lats"}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+}
+
+func TestMustRegister(t *testing.T) {
+	type Good struct {
+		Name string `njson:"name"`
+	}
+
+	type Bad struct {
+		Name int `njson:"names.#.first"`
+	}
+
+	MustRegister(Good{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustRegister should panic")
+		}
+	}()
+
+	MustRegister(Bad{})
+}
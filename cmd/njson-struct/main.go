@@ -0,0 +1,74 @@
+// Command njson-struct writes a Go struct with njson tags that reads a
+// sample JSON document, given as a file or on standard input
+//
+//	curl -s https://api.example.com/users/1 | njson-struct -name User -package api
+//	njson-struct -name User -path data.user.profile.name -path data.friends.#.name sample.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/m7shapan/njson/structgen"
+)
+
+// pathList collects the values of a flag given several times, as paths may
+// contain commas
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, " ")
+}
+
+func (p *pathList) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
+
+func main() {
+	var opts structgen.Options
+	var paths pathList
+
+	name := flag.String("name", "Root", "name of the struct")
+	output := flag.String("o", "", "output file, standard output if empty")
+	flag.StringVar(&opts.Package, "package", "", "package of the output; only the type declarations are written if empty")
+	flag.IntVar(&opts.Depth, "depth", 0, "how many levels of keys a field's path spans, 0 flattens objects completely")
+	flag.Var(&paths, "path", "path to map to a field, may be repeated; every value is mapped if none is given")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: njson-struct [flags] [sample.json]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	opts.Paths = paths
+
+	var sample []byte
+	var err error
+	if flag.NArg() > 0 {
+		sample, err = os.ReadFile(flag.Arg(0))
+	} else {
+		sample, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "njson-struct: %v\n", err)
+		os.Exit(1)
+	}
+
+	src, err := structgen.Generate(*name, sample, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "njson-struct: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "njson-struct: %v\n", err)
+		os.Exit(1)
+	}
+}
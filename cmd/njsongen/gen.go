@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/m7shapan/njson/internal/astutil"
+)
+
+// structType is a struct type declared in the package
+type structType struct {
+	name    string
+	fields  *ast.FieldList
+	imports map[string]string // local name to import path, in its file
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	structs map[string]*structType
+	gen     map[string]bool // types getting a decoder
+}
+
+// generate returns the source of the decoders for the named types of the
+// package in dir, or for all its structs with njson tags if types is empty.
+// The file named output is skipped as it holds the previous decoders
+func generate(dir, output string, types []string) ([]byte, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{structs: map[string]*structType{}, gen: map[string]bool{}}
+
+	var declared []string
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		if name == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		imports := fileImports(file)
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.TypeParams != nil {
+					continue
+				}
+
+				g.structs[ts.Name.Name] = &structType{name: ts.Name.Name, fields: st.Fields, imports: imports}
+				declared = append(declared, ts.Name.Name)
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		for _, name := range declared {
+			if hasTags(g.structs[name].fields) {
+				types = append(types, name)
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no struct with njson tags in %s", dir)
+	}
+
+	for _, name := range types {
+		if g.structs[name] == nil {
+			return nil, fmt.Errorf("struct type %s not found in %s", name, dir)
+		}
+		g.gen[name] = true
+	}
+
+	g.printf("// Code generated by njsongen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", pkg.Name)
+	g.printf("import (\n\"fmt\"\n\n\"github.com/m7shapan/njson\"\n\"github.com/tidwall/gjson\"\n)\n")
+
+	for _, name := range types {
+		g.decoder(g.structs[name])
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %v", err)
+	}
+
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// decoder writes the UnmarshalNJSON method of s, and the unmarshalNJSON
+// method it shares with the decoders of the structs holding s, which skips
+// validating data again
+func (g *generator) decoder(s *structType) {
+	g.printf("\n// UnmarshalNJSON implements njson.Unmarshaler\n")
+	g.printf("func (v *%s) UnmarshalNJSON(data []byte) error {\n", s.name)
+	g.printf("if !gjson.ValidBytes(data) {\nreturn fmt.Errorf(\"invalid json: %%v\", string(data))\n}\n\n")
+	g.printf("return v.unmarshalNJSON(data)\n}\n")
+
+	g.printf("\nfunc (v *%s) unmarshalNJSON(data []byte) error {\n", s.name)
+	g.printf("if hook, ok := interface{}(v).(njson.BeforeUnmarshaler); ok {\n")
+	g.printf("if err := hook.BeforeUnmarshalNJSON(gjson.ParseBytes(data)); err != nil {\nreturn njson.WrapFieldError(err, \"\", \"\")\n}\n}\n\n")
+
+	for _, field := range s.fields.List {
+		for _, name := range astutil.FieldNames(field) {
+			g.field(s, field, name)
+		}
+	}
+
+	g.printf("\nif hook, ok := interface{}(v).(njson.AfterUnmarshaler); ok {\n")
+	g.printf("if err := hook.AfterUnmarshalNJSON(); err != nil {\nreturn njson.WrapFieldError(err, \"\", \"\")\n}\n}\n\n")
+	g.printf("return nil\n}\n")
+}
+
+// field writes the code decoding the field name of s
+func (g *generator) field(s *structType, field *ast.Field, name string) {
+	tag := fieldTag(field)
+	path, ok := fieldPath(tag)
+	if !ok {
+		return
+	}
+
+	if path != "" {
+		lookup := fmt.Sprintf("gjson.GetBytes(data, %q)", path)
+		if conv := g.scalar(field.Type, s.imports, lookup); conv != "" {
+			g.printf("v.%s = %s\n", name, conv)
+			return
+		}
+
+		if nested := g.nested(field.Type); nested != "" {
+			g.printf("\nv.%s = %s{}\n", name, nested)
+			g.printf("if r := gjson.GetBytes(data, %q); r.Exists() && r.Type != gjson.Null {\n", path)
+			g.printf("if err := v.%s.unmarshalNJSON([]byte(r.Raw)); err != nil {\nreturn njson.WrapFieldError(err, %q, %q)\n}\n}\n\n", name, name, path)
+			return
+		}
+
+		if elem, ok := field.Type.(*ast.ArrayType); ok && elem.Len == nil {
+			if g.slice(s, name, path, elem.Elt) {
+				return
+			}
+		}
+	}
+
+	g.printf("\nif err := njson.UnmarshalField(data, v, %q); err != nil {\nreturn err\n}\n\n", name)
+}
+
+// slice writes the code decoding a slice field whose elements have a direct
+// conversion or a generated decoder, and reports whether it could
+func (g *generator) slice(s *structType, name, path string, elem ast.Expr) bool {
+	conv := g.scalar(elem, s.imports, "r")
+	nested := g.nested(elem)
+	if conv == "" && nested == "" {
+		return false
+	}
+
+	typ := typeString(elem)
+	g.printf("\nif r := gjson.GetBytes(data, %q); r.Type == gjson.Null && r.Exists() {\nv.%s = nil\n} else {\n", path, name)
+	g.printf("results := r.Array()\nv.%s = make([]%s, 0, len(results))\n", name, typ)
+	if conv != "" {
+		g.printf("for _, r := range results {\nv.%s = append(v.%s, %s)\n", name, name, conv)
+	} else {
+		// elements are reported as Field[i] read from path.i
+		field := strconv.Quote(name + "[%d]")
+		elemPath := strconv.Quote(strings.ReplaceAll(path, "%", "%%") + ".%d")
+		g.printf("for i, r := range results {\nvar e %s\nif r.Type != gjson.Null {\n", nested)
+		g.printf("if err := e.unmarshalNJSON([]byte(r.Raw)); err != nil {\n")
+		g.printf("return njson.WrapFieldError(err, fmt.Sprintf(%s, i), fmt.Sprintf(%s, i))\n}\n}\n", field, elemPath)
+		g.printf("v.%s = append(v.%s, e)\n", name, name)
+	}
+	g.printf("}\n}\n\n")
+
+	return true
+}
+
+// scalar returns the expression converting the gjson.Result r to the type
+// expr the way njson does, or "" if there is none
+func (g *generator) scalar(expr ast.Expr, imports map[string]string, r string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return r + ".String()"
+		case "bool":
+			return r + ".Bool()"
+		case "int64":
+			return r + ".Int()"
+		case "int", "int8", "int16", "int32", "rune":
+			return t.Name + "(" + r + ".Int())"
+		case "float64":
+			return r + ".Float()"
+		case "float32":
+			return "float32(" + r + ".Float())"
+		}
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok && imports[x.Name] == "time" && t.Sel.Name == "Time" {
+			return r + ".Time()"
+		}
+	}
+
+	return ""
+}
+
+// nested returns the name of the struct type expr if it gets a generated
+// decoder too, or ""
+func (g *generator) nested(expr ast.Expr) string {
+	if t, ok := expr.(*ast.Ident); ok && g.gen[t.Name] {
+		return t.Name
+	}
+
+	return ""
+}
+
+// typeString prints a type expression of the package being generated
+func typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+// fieldPath returns the gjson path a field is read from with the default
+// options, or "" if njson.UnmarshalField has to decode it: json tags,
+// JSON Pointers, JSONPath queries and tag options. ok is false for fields
+// njson ignores
+func fieldPath(tag reflect.StructTag) (path string, ok bool) {
+	switch {
+	case validTag(tag, "json"):
+		return "", true
+	case validTag(tag, "njson"):
+		path = tag.Get("njson")
+		if strings.Contains(path, ",") || strings.HasPrefix(path, "/") || strings.HasPrefix(path, "$") {
+			return "", true
+		}
+		return path, true
+	case validTag(tag, "njsonpath"):
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+func validTag(tag reflect.StructTag, name string) bool {
+	return !(tag.Get(name) == "" || tag.Get(name) == "-")
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+
+	return reflect.StructTag(tag)
+}
+
+// hasTags reports whether any field is decoded by njson
+func hasTags(fields *ast.FieldList) bool {
+	for _, field := range fields.List {
+		if _, ok := fieldPath(fieldTag(field)); ok && len(astutil.FieldNames(field)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fileImports maps the names imports are used by in file to their paths
+func fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+
+	return imports
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("internal", "sample")
+
+	actual, err := generate(dir, "njson_gen.go", []string{"User", "Friend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile(filepath.Join(dir, "njson_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the committed decoders must be up to date, run go generate otherwise
+	if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+
+	if _, err := generate(dir, "njson_gen.go", []string{"Missing"}); err == nil {
+		t.Error("error should not be nil for an unknown type")
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		tag  string
+		path string
+		ok   bool
+	}{
+		{tag: `njson:"name.first"`, path: "name.first", ok: true},
+		{tag: `njson:"name,upper"`, ok: true},
+		{tag: `njson:"/items/0"`, ok: true},
+		{tag: `njson:"$..author"`, ok: true},
+		{tag: `njsonpath:"store.book"`, ok: true},
+		{tag: `json:"name" njson:"name.first"`, ok: true},
+		{tag: `njson:"-"`},
+		{tag: `xml:"name"`},
+	}
+
+	for _, tt := range tests {
+		path, ok := fieldPath(reflect.StructTag(tt.tag))
+		if path != tt.path || ok != tt.ok {
+			t.Errorf("%s: expected %q %v, got %q %v", tt.tag, tt.path, tt.ok, path, ok)
+		}
+	}
+}
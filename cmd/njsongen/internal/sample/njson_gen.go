@@ -0,0 +1,148 @@
+// Code generated by njsongen. DO NOT EDIT.
+
+package sample
+
+import (
+	"fmt"
+
+	"github.com/m7shapan/njson"
+	"github.com/tidwall/gjson"
+)
+
+// UnmarshalNJSON implements njson.Unmarshaler
+func (v *User) UnmarshalNJSON(data []byte) error {
+	if !gjson.ValidBytes(data) {
+		return fmt.Errorf("invalid json: %v", string(data))
+	}
+
+	return v.unmarshalNJSON(data)
+}
+
+func (v *User) unmarshalNJSON(data []byte) error {
+	if hook, ok := interface{}(v).(njson.BeforeUnmarshaler); ok {
+		if err := hook.BeforeUnmarshalNJSON(gjson.ParseBytes(data)); err != nil {
+			return njson.WrapFieldError(err, "", "")
+		}
+	}
+
+	v.Name = gjson.GetBytes(data, "name.first").String()
+
+	if err := njson.UnmarshalField(data, v, "Last"); err != nil {
+		return err
+	}
+
+	v.Age = int(gjson.GetBytes(data, "age").Int())
+	v.Height = float32(gjson.GetBytes(data, "height").Float())
+	v.Admin = gjson.GetBytes(data, "admin").Bool()
+	v.Created = gjson.GetBytes(data, "created").Time()
+
+	if r := gjson.GetBytes(data, "children"); r.Type == gjson.Null && r.Exists() {
+		v.Children = nil
+	} else {
+		results := r.Array()
+		v.Children = make([]string, 0, len(results))
+		for _, r := range results {
+			v.Children = append(v.Children, r.String())
+		}
+	}
+
+	if r := gjson.GetBytes(data, "friends.#.age"); r.Type == gjson.Null && r.Exists() {
+		v.Ages = nil
+	} else {
+		results := r.Array()
+		v.Ages = make([]int64, 0, len(results))
+		for _, r := range results {
+			v.Ages = append(v.Ages, r.Int())
+		}
+	}
+
+	v.Best = Friend{}
+	if r := gjson.GetBytes(data, "friends.0"); r.Exists() && r.Type != gjson.Null {
+		if err := v.Best.unmarshalNJSON([]byte(r.Raw)); err != nil {
+			return njson.WrapFieldError(err, "Best", "friends.0")
+		}
+	}
+
+	if r := gjson.GetBytes(data, "friends"); r.Type == gjson.Null && r.Exists() {
+		v.Friends = nil
+	} else {
+		results := r.Array()
+		v.Friends = make([]Friend, 0, len(results))
+		for i, r := range results {
+			var e Friend
+			if r.Type != gjson.Null {
+				if err := e.unmarshalNJSON([]byte(r.Raw)); err != nil {
+					return njson.WrapFieldError(err, fmt.Sprintf("Friends[%d]", i), fmt.Sprintf("friends.%d", i))
+				}
+			}
+			v.Friends = append(v.Friends, e)
+		}
+	}
+
+	if err := njson.UnmarshalField(data, v, "Pointer"); err != nil {
+		return err
+	}
+
+	if err := njson.UnmarshalField(data, v, "Scores"); err != nil {
+		return err
+	}
+
+	if err := njson.UnmarshalField(data, v, "Movie"); err != nil {
+		return err
+	}
+
+	if err := njson.UnmarshalField(data, v, "Count"); err != nil {
+		return err
+	}
+
+	if err := njson.UnmarshalField(data, v, "Labels"); err != nil {
+		return err
+	}
+
+	if hook, ok := interface{}(v).(njson.AfterUnmarshaler); ok {
+		if err := hook.AfterUnmarshalNJSON(); err != nil {
+			return njson.WrapFieldError(err, "", "")
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalNJSON implements njson.Unmarshaler
+func (v *Friend) UnmarshalNJSON(data []byte) error {
+	if !gjson.ValidBytes(data) {
+		return fmt.Errorf("invalid json: %v", string(data))
+	}
+
+	return v.unmarshalNJSON(data)
+}
+
+func (v *Friend) unmarshalNJSON(data []byte) error {
+	if hook, ok := interface{}(v).(njson.BeforeUnmarshaler); ok {
+		if err := hook.BeforeUnmarshalNJSON(gjson.ParseBytes(data)); err != nil {
+			return njson.WrapFieldError(err, "", "")
+		}
+	}
+
+	v.First = gjson.GetBytes(data, "first").String()
+	v.Last = gjson.GetBytes(data, "last").String()
+	v.Age = int8(gjson.GetBytes(data, "age").Int())
+
+	if r := gjson.GetBytes(data, "nets"); r.Type == gjson.Null && r.Exists() {
+		v.Nets = nil
+	} else {
+		results := r.Array()
+		v.Nets = make([]string, 0, len(results))
+		for _, r := range results {
+			v.Nets = append(v.Nets, r.String())
+		}
+	}
+
+	if hook, ok := interface{}(v).(njson.AfterUnmarshaler); ok {
+		if err := hook.AfterUnmarshalNJSON(); err != nil {
+			return njson.WrapFieldError(err, "", "")
+		}
+	}
+
+	return nil
+}
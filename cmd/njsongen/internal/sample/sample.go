@@ -0,0 +1,56 @@
+// Package sample holds types decoded by generated code, to check it against
+// njson's reflection based decoding
+package sample
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+//go:generate go run github.com/m7shapan/njson/cmd/njsongen -type User,Friend
+
+type Friend struct {
+	First string   `njson:"first"`
+	Last  string   `njson:"last"`
+	Age   int8     `njson:"age"`
+	Nets  []string `njson:"nets"`
+}
+
+type User struct {
+	Name     string            `njson:"name.first"`
+	Last     string            `njson:"name.last,upper"`
+	Age      int               `njson:"age"`
+	Height   float32           `njson:"height"`
+	Admin    bool              `njson:"admin"`
+	Created  time.Time         `njson:"created"`
+	Children []string          `njson:"children"`
+	Ages     []int64           `njson:"friends.#.age"`
+	Best     Friend            `njson:"friends.0"`
+	Friends  []Friend          `njson:"friends"`
+	Pointer  string            `njson:"/name/first"`
+	Scores   map[string]int    `njson:"scores"`
+	Movie    string            `json:"fav.movie"`
+	Count    uint              `njson:"friends.#"`
+	Labels   map[string]string `njson:"missing"`
+	Initials string
+
+	full string
+}
+
+func (f *Friend) AfterUnmarshalNJSON() error {
+	if f.Age < 0 {
+		return errors.New("negative age")
+	}
+	return nil
+}
+
+func (u *User) AfterUnmarshalNJSON() error {
+	u.full = strings.TrimSpace(u.Name + " " + u.Last)
+	return nil
+}
+
+// Full is the name computed once decoded
+func (u *User) Full() string {
+	return u.full
+}
@@ -0,0 +1,78 @@
+package sample
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/m7shapan/njson"
+)
+
+func TestGeneratedDecoder(t *testing.T) {
+	json := `
+	{
+		"name": {"first": "Tom", "last": "Anderson"},
+		"age": "37",
+		"height": 1.82,
+		"admin": true,
+		"created": "2021-06-01T10:00:00Z",
+		"children": ["Sara", null, 3],
+		"fav.movie": "Deer Hunter",
+		"scores": {"math": 90},
+		"friends": [
+			{"first": "Dale", "last": "Murphy", "age": 44, "nets": ["ig", "fb"]},
+			null,
+			{"first": "Jane", "age": 47, "nets": "tw"}
+		]
+	}`
+
+	generated := User{Initials: "TA"}
+	if err := njson.Unmarshal([]byte(json), &generated); err != nil {
+		t.Fatal(err)
+	}
+
+	// any option makes njson decode with reflection
+	reflected := User{Initials: "TA"}
+	if err := (njson.UnmarshalOptions{PresentFields: map[string]bool{}}).Unmarshal([]byte(json), &reflected); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(reflected, generated, cmp.AllowUnexported(User{})); diff != "" {
+		t.Errorf("(-reflected, +generated): %s", diff)
+	}
+
+	if generated.Full() != "Tom ANDERSON" {
+		t.Errorf("after hook should set the full name, got %q", generated.Full())
+	}
+
+	if err := njson.Unmarshal([]byte(`{"name": `), &generated); err == nil {
+		t.Error("error should not be nil for invalid json")
+	}
+}
+
+func TestGeneratedErrors(t *testing.T) {
+	tests := []string{
+		`{"friends": [{"age": -1}]}`,
+		`{"friends": [{"age": 1}, {"age": -1}]}`,
+		`{"friends": [{"age": 1}, {"age": 2}], "scores": {"math": "a"}}`,
+	}
+
+	for _, json := range tests {
+		generated := njson.Unmarshal([]byte(json), &User{})
+		reflected := (njson.UnmarshalOptions{PresentFields: map[string]bool{}}).Unmarshal([]byte(json), &User{})
+
+		if generated == nil || reflected == nil {
+			t.Errorf("%s: errors should not be nil, got %v and %v", json, generated, reflected)
+			continue
+		}
+
+		if generated.Error() != reflected.Error() {
+			t.Errorf("%s: generated error %q, reflection gives %q", json, generated, reflected)
+		}
+
+		var fe *njson.FieldError
+		if !errors.As(generated, &fe) {
+			t.Errorf("%s: error should be a *FieldError, got %v", json, generated)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Command njsongen generates reflection-free decoders for structs with njson
+// tags. For each type it writes an UnmarshalNJSON method, which
+// njson.Unmarshal detects and calls instead of decoding with reflection
+//
+//	//go:generate njsongen -type User,Friend
+//
+// Fields of basic types, time.Time, slices of those and structs generated in
+// the same run are decoded with gjson directly. Any other field, including
+// fields with tag options, is left to njson.UnmarshalField
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of type names; all structs with njson tags if empty")
+	output := flag.String("output", "njson_gen.go", "output file name, relative to the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: njsongen [-type T,U] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	src, err := generate(dir, filepath.Base(*output), types)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "njsongen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, *output), src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "njsongen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+package njson
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type]map[string]interface{}{}
+)
+
+// RegisterEnum makes fields, slice elements and map values of type typ
+// decode from one of the strings in values, e.g.
+//
+//	njson.RegisterEnum(reflect.TypeOf(Status(0)), map[string]interface{}{
+//		"active":   StatusActive,
+//		"inactive": StatusInactive,
+//	})
+//
+// Any other value is reported as an error. The values must be convertible
+// to typ
+func RegisterEnum(typ reflect.Type, values map[string]interface{}) {
+	for name, v := range values {
+		if !reflect.TypeOf(v).ConvertibleTo(typ) {
+			panic(fmt.Errorf("enum value %q of type %T can't be converted to %v", name, v, typ))
+		}
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+
+	enums[typ] = values
+}
+
+func registeredEnum(typ reflect.Type) map[string]interface{} {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+
+	return enums[typ]
+}
+
+// decodeEnum sets field from an enum, given by the "enum" tag option, such
+// as `njson:"status,enum=active|inactive"`, or registered for its type, and
+// reports whether it did so. With the tag option a string field is set to the
+// value itself and an integer field to the value's index in the list
+func (d *decodeState) decodeEnum(result gjson.Result, field reflect.Value, f fieldInfo) bool {
+	var allowed []string
+	values := registeredEnum(field.Type())
+
+	if f.opts.has("enum") {
+		if isStructureType(field.Kind().String()) {
+			return false
+		}
+
+		allowed = strings.Split(f.opts.get("enum"), "|")
+		values = make(map[string]interface{}, len(allowed))
+		for i, name := range allowed {
+			switch field.Kind() {
+			case reflect.String:
+				values[name] = name
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				values[name] = i
+			default:
+				panic(fmt.Errorf("enum option can't be used with %v", field.Type()))
+			}
+		}
+	} else if values != nil {
+		for name := range values {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+	} else {
+		return false
+	}
+
+	if !result.Exists() || result.Type == gjson.Null {
+		field.Set(reflect.Zero(field.Type()))
+		return true
+	}
+
+	v, ok := values[result.String()]
+	if result.Type != gjson.String || !ok {
+		panic(fmt.Errorf("invalid value %s for %v, allowed: %s", result.Raw, field.Type(), strings.Join(allowed, ", ")))
+	}
+
+	field.Set(reflect.ValueOf(v).Convert(field.Type()))
+	return true
+}
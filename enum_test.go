@@ -0,0 +1,81 @@
+package njson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusActive
+	StatusBanned
+)
+
+func TestUnmarshalEnum(t *testing.T) {
+	RegisterEnum(reflect.TypeOf(Status(0)), map[string]interface{}{
+		"active": StatusActive,
+		"banned": StatusBanned,
+	})
+
+	json := `
+	{
+		"status": "banned",
+		"role": "admin",
+		"level": "gold",
+		"history": ["active", "banned"],
+		"by_year": {"2020": "active", "2021": "banned"},
+		"roles": {"a": "user", "b": "admin"}
+	}`
+
+	type Account struct {
+		Status  Status            `njson:"status"`
+		Role    string            `njson:"role,enum=user|admin"`
+		Level   int               `njson:"level,enum=silver|gold"`
+		History []Status          `njson:"history"`
+		ByYear  map[int]Status    `njson:"by_year"`
+		Roles   map[string]string `njson:"roles,enum=user|admin"`
+	}
+
+	actual := Account{}
+
+	err := Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Account{
+		Status:  StatusBanned,
+		Role:    "admin",
+		Level:   1,
+		History: []Status{StatusActive, StatusBanned},
+		ByYear:  map[int]Status{2020: StatusActive, 2021: StatusBanned},
+		Roles:   map[string]string{"a": "user", "b": "admin"},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	invalid := `{"status": "active", "history": ["active", "deleted"], "roles": {"a": "root"}}`
+
+	err = UnmarshalOptions{CollectErrors: true}.Unmarshal([]byte(invalid), &Account{})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var paths []string
+	for _, fe := range errs {
+		paths = append(paths, fe.Path)
+	}
+
+	if diff := cmp.Diff([]string{"history.1", "roles.a"}, paths); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,100 @@
+package njson
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a field that could not be decoded
+type FieldError struct {
+	// Field is the Go path of the field, e.g. "Friends[1].Age"
+	Field string
+	// Path is the njson path the value was read from, e.g. "friends.1.age"
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" && e.Path == "" {
+		return e.Err.Error()
+	}
+
+	if e.Field == "" {
+		return fmt.Sprintf("path %q: %v", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("field %s (path %q): %v", e.Field, e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is returned by UnmarshalOptions.Unmarshal when CollectErrors is set
+// and one or more fields failed, by Validate and by schema validation. It
+// works with errors.Is and errors.As the same way a value built by
+// errors.Join does
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the field errors matches target, so errors.Is
+// looks into Errors before Go 1.20 too
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first field error that matches target, so errors.As looks
+// into Errors before Go 1.20 too
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unwrap returns the individual field errors, for Go 1.20 and later
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// ElementError describes a slice element that failed to decode and was
+// skipped or zeroed instead of failing the decode
+type ElementError struct {
+	// Field is the Go path of the slice field
+	Field string
+	// Path is the njson path of the slice
+	Path  string
+	Index int
+	Err   error
+}
+
+func (e ElementError) Error() string {
+	return fmt.Sprintf("field %s[%d] (path %q): %v", e.Field, e.Index, e.Path, e.Err)
+}
+
+func (e ElementError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,128 @@
+package njson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshalCollectErrors(t *testing.T) {
+	json := `
+	{
+		"name": {"first": "Mohamed", "last": "Shapan"},
+		"age": 26,
+		"labels": "not a map",
+		"custom": 42,
+		"friends": [
+			{"first": "Asma", "meta": {"a": 1}},
+			{"first": "Ahmed", "meta": [1, 2]},
+			"Mahmoud"
+		]
+	}`
+
+	type Friend struct {
+		First string         `njson:"first"`
+		Meta  map[string]int `njson:"meta"`
+	}
+
+	type User struct {
+		First   string                `njson:"name.first"`
+		Age     int                   `njson:"age"`
+		Labels  map[string]string     `njson:"labels"`
+		Custom  CustomUnmarshalerType `njson:"custom"`
+		Friends []Friend              `njson:"friends"`
+		Last    string                `njson:"name.last"`
+	}
+
+	t.Run("first error", func(t *testing.T) {
+		actual := User{}
+
+		err := Unmarshal([]byte(json), &actual)
+
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			t.Fatalf("error should be a *FieldError, got %v", err)
+		}
+
+		if fe.Field != "Labels" || fe.Path != "labels" {
+			t.Errorf("unexpected field error: %v", fe)
+		}
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		actual := User{}
+
+		err := UnmarshalOptions{CollectErrors: true}.Unmarshal([]byte(json), &actual)
+
+		var errs Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("error should be Errors, got %v", err)
+		}
+
+		var fields []string
+		for _, fe := range errs {
+			fields = append(fields, fe.Field+" "+fe.Path)
+		}
+
+		expectedFields := []string{
+			"Labels labels",
+			"Custom custom",
+			"Friends[1].Meta friends.1.meta",
+		}
+
+		if diff := cmp.Diff(expectedFields, fields); diff != "" {
+			t.Error(diff)
+		}
+
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe != errs[0] {
+			t.Error("errors.As should find the first field error")
+		}
+
+		expected := User{
+			First: "Mohamed",
+			Age:   26,
+			Friends: []Friend{
+				{First: "Asma", Meta: map[string]int{"a": 1}},
+				{First: "Ahmed"},
+				{},
+			},
+			Last: "Shapan",
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	errMissing := errors.New("missing")
+	rule := &RuleError{Rule: "min", Param: "1", Value: 0}
+
+	errs := Errors{
+		{Field: "Name", Path: "name", Err: errMissing},
+		{Field: "Age", Path: "age", Err: rule},
+	}
+
+	// call the methods directly, errors.Is and errors.As only rely on them
+	// before Go 1.20
+	if !errs.Is(errMissing) {
+		t.Error("Is should find a wrapped error")
+	}
+
+	if errs.Is(errors.New("other")) {
+		t.Error("Is should not match an unrelated error")
+	}
+
+	var re *RuleError
+	if !errs.As(&re) || re != rule {
+		t.Error("As should find the rule error")
+	}
+
+	var fe *FieldError
+	if !errs.As(&fe) || fe != errs[0] {
+		t.Error("As should find the first field error")
+	}
+}
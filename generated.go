@@ -0,0 +1,64 @@
+package njson
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Unmarshaler is implemented by types with a decoder generated by
+// cmd/njsongen. Unmarshal calls UnmarshalNJSON rather than decoding such a
+// type with reflection, as long as no option is set since generated
+// decoders only implement the default behaviour
+type Unmarshaler interface {
+	UnmarshalNJSON(data []byte) error
+}
+
+// generated reports whether a decode configured by o may use generated
+// decoders
+func (o UnmarshalOptions) generated() bool {
+	return reflect.ValueOf(o).IsZero()
+}
+
+// UnmarshalField decodes data into the field name of the struct v points
+// to, exactly as Unmarshal would. Generated decoders call it for the fields
+// they leave to reflection, such as those with tag options
+func UnmarshalField(data []byte, v interface{}, name string) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can't unmarshal to invalid type %v", reflect.TypeOf(v))
+	}
+
+	sf, ok := rv.Elem().Type().FieldByName(name)
+	if !ok || len(sf.Index) != 1 {
+		return fmt.Errorf("%v has no field %s", rv.Elem().Type(), name)
+	}
+
+	// catch code panic and return error message
+	defer catchPanic(&err)
+
+	d := &decodeState{}
+	d.decodeStructField(data, rv.Elem(), sf.Index[0], nil)
+
+	return
+}
+
+// WrapFieldError returns err as Unmarshal reports it for the value read
+// into field from path: the field errors of a nested struct get field and
+// path as prefix, other errors are wrapped in a *FieldError. Generated
+// decoders use it so their errors match those of reflection
+func WrapFieldError(err error, field, path string) error {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		return &FieldError{Field: field, Path: path, Err: err}
+	}
+
+	// a hook error of the nested struct itself has no field of its own
+	if fe.Field != "" {
+		field = joinField(field, fe.Field)
+	}
+	if fe.Path != "" {
+		path = joinPath(path, fe.Path)
+	}
+
+	return &FieldError{Field: field, Path: path, Err: fe.Err}
+}
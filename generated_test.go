@@ -0,0 +1,80 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tidwall/gjson"
+)
+
+// handwritten stands for a type with a generated decoder
+type handwritten struct {
+	Name  string `njson:"name"`
+	Calls int
+}
+
+func (h *handwritten) UnmarshalNJSON(data []byte) error {
+	h.Name = gjson.GetBytes(data, "name").String()
+	h.Calls++
+	return UnmarshalField(data, h, "Name")
+}
+
+func TestUnmarshalGenerated(t *testing.T) {
+	json := `{"name": "Asma", "friend": {"name": "Ahmed"}}`
+
+	actual := handwritten{}
+	if err := Unmarshal([]byte(json), &actual); err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(handwritten{Name: "Asma", Calls: 1}, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	// options the generated code can't honour fall back to reflection
+	actual = handwritten{}
+	if err := (UnmarshalOptions{Strict: true}).Unmarshal([]byte(json), &actual); err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(handwritten{Name: "Asma"}, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	type Parent struct {
+		Friend handwritten `njson:"friend"`
+	}
+
+	parent := Parent{}
+	if err := Unmarshal([]byte(json), &parent); err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(Parent{Friend: handwritten{Name: "Ahmed", Calls: 1}}, parent); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalField(t *testing.T) {
+	type User struct {
+		Name string `njson:"name.first,upper"`
+		Age  int    `njson:"age"`
+	}
+
+	actual := User{}
+	if err := UnmarshalField([]byte(`{"name": {"first": "asma"}, "age": 30}`), &actual, "Name"); err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(User{Name: "ASMA"}, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := UnmarshalField([]byte(`{}`), &actual, "Missing"); err == nil {
+		t.Error("error should not be nil for an unknown field")
+	}
+
+	if err := UnmarshalField([]byte(`{}`), actual, "Name"); err == nil {
+		t.Error("error should not be nil for a non pointer")
+	}
+}
@@ -0,0 +1,39 @@
+package njson
+
+import "reflect"
+
+// Get returns the value at path converted to T, using the same conversion
+// rules Unmarshal applies to a field of type T. path is anything a njson tag
+// accepts, as with Lookup
+func Get[T any](data []byte, path string) (v T, err error) {
+	result, err := Lookup(data, path)
+	if err != nil {
+		return v, err
+	}
+
+	// catch code panic and return error message
+	defer catchPanic(&err)
+
+	d := &decodeState{}
+	d.decodeField(result, reflect.ValueOf(&v).Elem(), fieldInfo{tag: path})
+
+	return
+}
+
+// Decode unmarshals data into a new value of type T using "njson" tags
+func Decode[T any](data []byte) (v T, err error) {
+	err = Unmarshal(data, &v)
+	return
+}
+
+// Must returns v if err is nil and panics otherwise. It is intended for
+// values whose shape is known to be valid, such as
+//
+//	age := njson.Must(njson.Get[int](data, "user.age"))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
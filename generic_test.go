@@ -0,0 +1,106 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGet(t *testing.T) {
+	json := `
+	{
+        "name": {"first": "Mohamed", "last": "Shapan"},
+        "age": 26,
+        "friends": [
+            {"first": "Asma", "age": 26},
+            {"first": "Ahmed", "age": 25},
+            {"first": "Mahmoud", "age": 30}
+        ]
+	}`
+
+	type Name struct {
+		First string `njson:"first"`
+		Last  string `njson:"last"`
+	}
+
+	age, err := Get[int]([]byte(json), "age")
+	if err != nil {
+		t.Error(err)
+	}
+	if age != 26 {
+		t.Errorf("age should be 26, got %d", age)
+	}
+
+	name, err := Get[Name]([]byte(json), "name")
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(Name{First: "Mohamed", Last: "Shapan"}, name); diff != "" {
+		t.Error(diff)
+	}
+
+	friends, err := Get[[]string]([]byte(json), "friends.#.first")
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff([]string{"Asma", "Ahmed", "Mahmoud"}, friends); diff != "" {
+		t.Error(diff)
+	}
+
+	second, err := Get[string]([]byte(json), "/friends/1/first")
+	if err != nil {
+		t.Error(err)
+	}
+	if second != "Ahmed" {
+		t.Errorf("second friend should be Ahmed, got %s", second)
+	}
+
+	ages, err := Get[[]int]([]byte(json), "$.friends[?@.age > 25].age")
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff([]int{26, 30}, ages); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := Get[int]([]byte(`{"age":`), "age"); err == nil {
+		t.Error("error should not be nil")
+	}
+
+	if _, err := Get[string]([]byte(json), "/name/~2"); err == nil {
+		t.Error("error should not be nil for an invalid path")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	json := `{"name": {"first": "Mohamed", "last": "Shapan"}, "age": 26}`
+
+	type User struct {
+		Name string `njson:"name.first"`
+		Age  int    `njson:"age"`
+	}
+
+	actual, err := Decode[User]([]byte(json))
+	if err != nil {
+		t.Error(err)
+	}
+
+	diff := cmp.Diff(User{Name: "Mohamed", Age: 26}, actual)
+	if diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestMust(t *testing.T) {
+	if v := Must(Get[string]([]byte(`{"a": "b"}`), "a")); v != "b" {
+		t.Errorf("value should be b, got %s", v)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Must should panic on error")
+		}
+	}()
+
+	Must(Get[string]([]byte(`{"a": `), "a"))
+}
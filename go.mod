@@ -1,8 +1,13 @@
 module github.com/m7shapan/njson
 
-go 1.14
+go 1.18
 
 require (
 	github.com/google/go-cmp v0.5.6
 	github.com/tidwall/gjson v1.12.1
 )
+
+require (
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.0 // indirect
+)
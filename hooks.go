@@ -0,0 +1,58 @@
+package njson
+
+import (
+	"reflect"
+
+	"github.com/tidwall/gjson"
+)
+
+// BeforeUnmarshaler is implemented by structs that want to look at, or
+// reject, the document they are about to be filled from
+type BeforeUnmarshaler interface {
+	BeforeUnmarshalNJSON(raw gjson.Result) error
+}
+
+// AfterUnmarshaler is implemented by structs that compute derived fields or
+// check invariants once njson has filled them
+type AfterUnmarshaler interface {
+	AfterUnmarshalNJSON() error
+}
+
+// Validator is implemented by structs that check themselves after decoding.
+// It is only called when UnmarshalOptions.CallValidate is set
+type Validator interface {
+	Validate() error
+}
+
+// beforeHook calls BeforeUnmarshalNJSON on the struct elem, if it has one,
+// and reports whether the struct should be decoded
+func (d *decodeState) beforeHook(data []byte, elem reflect.Value, f fieldInfo) bool {
+	hook, isHook := elem.Addr().Interface().(BeforeUnmarshaler)
+	if !isHook {
+		return true
+	}
+
+	if err := hook.BeforeUnmarshalNJSON(gjson.ParseBytes(data)); err != nil {
+		d.fail(f, err)
+		return false
+	}
+
+	return true
+}
+
+// afterHooks calls AfterUnmarshalNJSON and, if asked to, Validate on the
+// struct elem once all of its fields are set
+func (d *decodeState) afterHooks(elem reflect.Value, f fieldInfo) {
+	if hook, ok := elem.Addr().Interface().(AfterUnmarshaler); ok {
+		if err := hook.AfterUnmarshalNJSON(); err != nil {
+			d.fail(f, err)
+			return
+		}
+	}
+
+	if v, ok := elem.Addr().Interface().(Validator); ok && d.opts.CallValidate {
+		if err := v.Validate(); err != nil {
+			d.fail(f, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package njson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tidwall/gjson"
+)
+
+type hookedName struct {
+	First string `njson:"first"`
+	Last  string `njson:"last"`
+	Full  string
+}
+
+func (n *hookedName) BeforeUnmarshalNJSON(raw gjson.Result) error {
+	if !raw.Get("first").Exists() {
+		return errors.New("first name is required")
+	}
+	return nil
+}
+
+func (n *hookedName) AfterUnmarshalNJSON() error {
+	n.Full = strings.TrimSpace(n.First + " " + n.Last)
+	return nil
+}
+
+type hookedUser struct {
+	Name    hookedName   `njson:"name"`
+	Friends []hookedName `njson:"friends"`
+	Age     int          `njson:"age"`
+}
+
+func (u *hookedUser) Validate() error {
+	if u.Age < 18 {
+		return errors.New("too young")
+	}
+	return nil
+}
+
+func TestUnmarshalHooks(t *testing.T) {
+	json := `
+	{
+		"name": {"first": "Mohamed", "last": "Shapan"},
+		"age": 16,
+		"friends": [
+			{"first": "Asma"},
+			{"last": "Ahmed"}
+		]
+	}`
+
+	actual := hookedUser{}
+
+	err := UnmarshalOptions{CollectErrors: true, CallValidate: true}.Unmarshal([]byte(json), &actual)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var messages []string
+	for _, fe := range errs {
+		messages = append(messages, fe.Error())
+	}
+
+	expectedMessages := []string{
+		`field Friends[1] (path "friends.1"): first name is required`,
+		`too young`,
+	}
+
+	if diff := cmp.Diff(expectedMessages, messages); diff != "" {
+		t.Error(diff)
+	}
+
+	expected := hookedUser{
+		Name:    hookedName{First: "Mohamed", Last: "Shapan", Full: "Mohamed Shapan"},
+		Friends: []hookedName{{First: "Asma", Full: "Asma"}, {}},
+		Age:     16,
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := Unmarshal([]byte(`{"name": {"first": "Mohamed"}, "age": 16}`), &hookedUser{}); err != nil {
+		t.Errorf("Validate should only be called with CallValidate: %v", err)
+	}
+}
+
+func TestUnmarshalPointerHooks(t *testing.T) {
+	type User struct {
+		Name    *hookedName   `njson:"name"`
+		Friends []*hookedName `njson:"friends"`
+		Partner *hookedName   `njson:"partner"`
+	}
+
+	actual := User{}
+
+	err := Unmarshal([]byte(`{"name": {"first": "Mohamed", "last": "Shapan"}, "friends": [{"first": "Asma"}, null]}`), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := User{
+		Name:    &hookedName{First: "Mohamed", Last: "Shapan", Full: "Mohamed Shapan"},
+		Friends: []*hookedName{{First: "Asma", Full: "Asma"}, nil},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	err = Unmarshal([]byte(`{"friends": [{"first": "Asma"}, {"last": "Ahmed"}]}`), &User{})
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Friends[1]" {
+		t.Errorf("hook error should be reported for Friends[1], got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+// Package astutil holds the syntax helpers shared by njson's tools, which
+// read struct declarations without type checking them
+package astutil
+
+import "go/ast"
+
+// FieldNames returns the exported names a field declares, embedded fields
+// being named after their type. njson ignores the others
+func FieldNames(field *ast.Field) (names []string) {
+	idents := field.Names
+	if len(idents) == 0 {
+		if ident := embeddedName(field.Type); ident != nil {
+			idents = []*ast.Ident{ident}
+		}
+	}
+
+	for _, ident := range idents {
+		if ident.IsExported() {
+			names = append(names, ident.Name)
+		}
+	}
+
+	return names
+}
+
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+// Package tagpath holds the syntax of the paths in njson tags, shared by
+// the decoder and the tools generating code and types from them
+package tagpath
+
+import "strings"
+
+// IsPointer reports whether path is a JSON Pointer (RFC 6901), such as
+// "/items/0/name"
+func IsPointer(path string) bool {
+	return strings.HasPrefix(path, "/")
+}
+
+// Split splits a gjson path into its components. seps holds the '.' or
+// '|' that precedes each component, 0 for the first one. Separators inside
+// brackets, braces, parentheses, quotes or escaped with '\' don't split
+func Split(path string) (comps []string, seps []byte) {
+	depth := 0
+	quoted := false
+	start := 0
+	var sep byte
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[' || c == '{' || c == '(':
+			depth++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+		case (c == '.' || c == '|') && depth == 0:
+			comps = append(comps, path[start:i])
+			seps = append(seps, sep)
+			sep = c
+			start = i + 1
+		}
+	}
+
+	return append(comps, path[start:]), append(seps, sep)
+}
+
+// Join is the inverse of Split
+func Join(comps []string, seps []byte) string {
+	var b strings.Builder
+	for i, comp := range comps {
+		if seps[i] != 0 {
+			b.WriteByte(seps[i])
+		}
+		b.WriteString(comp)
+	}
+
+	return b.String()
+}
+
+// IsPlainKey reports whether comp names a single object key, rather than
+// an array query, a modifier, a multipath or a wildcard pattern
+func IsPlainKey(comp string) bool {
+	if comp == "" || strings.ContainsAny(comp[:1], "#@[{!") {
+		return false
+	}
+
+	for i := 0; i < len(comp); i++ {
+		switch comp[i] {
+		case '\\':
+			i++
+		case '*', '?':
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnescapeKey returns the object key a plain path component stands for
+func UnescapeKey(comp string) string {
+	if !strings.Contains(comp, `\`) {
+		return comp
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(comp); i++ {
+		if comp[i] == '\\' && i+1 < len(comp) {
+			i++
+		}
+		b.WriteByte(comp[i])
+	}
+
+	return b.String()
+}
+
+// EscapeKey escapes the characters gjson gives a meaning to, so key is
+// matched literally
+func EscapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c < 0x80 && !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// IsIndex reports whether comp is an array index
+func IsIndex(comp string) bool {
+	if comp == "" {
+		return false
+	}
+
+	for i := 0; i < len(comp); i++ {
+		if comp[i] < '0' || comp[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsJSONPath reports whether a njson tag path is a JSONPath query (RFC 9535)
+// such as `$.store.book[?@.price < 10].title`. Keys that merely start with a
+// '$', such as "$schema", remain gjson paths
+func IsJSONPath(path string) bool {
+	return path == "$" || strings.HasPrefix(path, "$.") || strings.HasPrefix(path, "$[")
+}
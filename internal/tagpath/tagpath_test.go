@@ -0,0 +1,20 @@
+package tagpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplit(t *testing.T) {
+	comps, seps := Split(`friends.#(last=="a.b")#.first|@reverse.fav\.movie`)
+
+	expected := []string{"friends", `#(last=="a.b")#`, "first", "@reverse", `fav\.movie`}
+	if diff := cmp.Diff(expected, comps); diff != "" {
+		t.Error(diff)
+	}
+
+	if diff := cmp.Diff([]byte{0, '.', '.', '|', '.'}, seps); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,899 @@
+package njson
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// jsonPaths caches compiled JSONPath queries by their text
+var jsonPaths sync.Map // map[string]*jsonPath
+
+// Lookup returns the value at path in data, path being anything a njson tag
+// accepts: a gjson path, a JSON Pointer or a JSONPath query
+func Lookup(data []byte, path string) (gjson.Result, error) {
+	if !gjson.ValidBytes(data) {
+		return gjson.Result{}, fmt.Errorf("invalid json: %v", string(data))
+	}
+
+	path, err := checkTagPath(path, nil)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	d := &decodeState{}
+	return d.lookup(data, path)
+}
+
+// getJSONPath evaluates the JSONPath query on data. A singular query, one
+// made only of names and indices, gives the node it selects, any other query
+// gives an array of all the nodes it selects
+func getJSONPath(data []byte, query string) (gjson.Result, error) {
+	p, err := compileJSONPath(query)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	root := gjson.ParseBytes(data)
+	nodes := p.eval(root, root)
+
+	if p.singular() {
+		if len(nodes) == 0 {
+			return gjson.Result{}, nil
+		}
+		return nodes[0], nil
+	}
+
+	return arrayResult(nodes), nil
+}
+
+func compileJSONPath(query string) (*jsonPath, error) {
+	if p, ok := jsonPaths.Load(query); ok {
+		return p.(*jsonPath), nil
+	}
+
+	ps := &jpParser{src: query}
+	p, err := ps.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonpath %q: %w", query, err)
+	}
+
+	jsonPaths.Store(query, p)
+	return p, nil
+}
+
+// jsonPath is a compiled query, absolute ($) or relative to the current
+// node of a filter (@)
+type jsonPath struct {
+	relative bool
+	segments []jpSegment
+}
+
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+type jpSelectorKind int
+
+const (
+	jpName jpSelectorKind = iota
+	jpWildcard
+	jpIndex
+	jpSlice
+	jpFilter
+)
+
+type jpSelector struct {
+	kind   jpSelectorKind
+	name   string
+	index  int
+	slice  [3]*int // start, end, step
+	filter jpExpr
+}
+
+// singular reports whether the query selects at most one node
+func (p *jsonPath) singular() bool {
+	for _, seg := range p.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+
+		if k := seg.selectors[0].kind; k != jpName && k != jpIndex {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *jsonPath) eval(root, cur gjson.Result) []gjson.Result {
+	nodes := []gjson.Result{root}
+	if p.relative {
+		nodes = []gjson.Result{cur}
+	}
+
+	for _, seg := range p.segments {
+		var next []gjson.Result
+		for _, n := range nodes {
+			if !seg.descendant {
+				next = seg.apply(root, n, next)
+				continue
+			}
+
+			for _, d := range descendants(n, nil) {
+				next = seg.apply(root, d, next)
+			}
+		}
+		nodes = next
+	}
+
+	return nodes
+}
+
+// apply appends the nodes the segment's selectors select from n to out
+func (seg jpSegment) apply(root, n gjson.Result, out []gjson.Result) []gjson.Result {
+	for _, sel := range seg.selectors {
+		switch sel.kind {
+		case jpName:
+			if v := member(n, sel.name); v.Exists() {
+				out = append(out, v)
+			}
+		case jpWildcard:
+			out = append(out, children(n)...)
+		case jpIndex:
+			if !n.IsArray() {
+				continue
+			}
+			elems := n.Array()
+			i := sel.index
+			if i < 0 {
+				i += len(elems)
+			}
+			if i >= 0 && i < len(elems) {
+				out = append(out, elems[i])
+			}
+		case jpSlice:
+			if n.IsArray() {
+				out = append(out, slice(n.Array(), sel.slice)...)
+			}
+		case jpFilter:
+			for _, c := range children(n) {
+				if sel.filter.test(root, c) {
+					out = append(out, c)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// children returns the member values of an object or the elements of an
+// array, in document order
+func children(n gjson.Result) []gjson.Result {
+	if !n.IsObject() && !n.IsArray() {
+		return nil
+	}
+
+	var out []gjson.Result
+	n.ForEach(func(_, value gjson.Result) bool {
+		out = append(out, value)
+		return true
+	})
+
+	return out
+}
+
+// descendants appends n and all of its descendants to out, depth first
+func descendants(n gjson.Result, out []gjson.Result) []gjson.Result {
+	out = append(out, n)
+	for _, c := range children(n) {
+		out = descendants(c, out)
+	}
+
+	return out
+}
+
+func member(n gjson.Result, name string) (v gjson.Result) {
+	if !n.IsObject() {
+		return
+	}
+
+	n.ForEach(func(key, value gjson.Result) bool {
+		if key.String() == name {
+			v = value
+			return false
+		}
+		return true
+	})
+
+	return
+}
+
+// slice selects elems[start:end:step] the way RFC 9535 defines it
+func slice(elems []gjson.Result, bounds [3]*int) (out []gjson.Result) {
+	n := len(elems)
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil
+	}
+
+	normalize := func(i int) int {
+		if i < 0 {
+			return n + i
+		}
+		return i
+	}
+	clamp := func(i, lo, hi int) int {
+		if i < lo {
+			return lo
+		}
+		if i > hi {
+			return hi
+		}
+		return i
+	}
+
+	if step > 0 {
+		start, end := 0, n
+		if bounds[0] != nil {
+			start = clamp(normalize(*bounds[0]), 0, n)
+		}
+		if bounds[1] != nil {
+			end = clamp(normalize(*bounds[1]), 0, n)
+		}
+		for i := start; i < end; i += step {
+			out = append(out, elems[i])
+		}
+		return out
+	}
+
+	start, end := n-1, -1
+	if bounds[0] != nil {
+		start = clamp(normalize(*bounds[0]), -1, n-1)
+	}
+	if bounds[1] != nil {
+		end = clamp(normalize(*bounds[1]), -1, n-1)
+	}
+	for i := start; i > end; i += step {
+		out = append(out, elems[i])
+	}
+
+	return out
+}
+
+// jpExpr is a logical filter expression
+type jpExpr interface {
+	test(root, cur gjson.Result) bool
+}
+
+// jpOperand is anything a filter compares or tests: a literal, a query or a
+// function call. It evaluates to a node list, a missing value being empty
+type jpOperand interface {
+	eval(root, cur gjson.Result) []gjson.Result
+}
+
+type jpOr []jpExpr
+
+func (e jpOr) test(root, cur gjson.Result) bool {
+	for _, x := range e {
+		if x.test(root, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+type jpAnd []jpExpr
+
+func (e jpAnd) test(root, cur gjson.Result) bool {
+	for _, x := range e {
+		if !x.test(root, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+type jpNot struct{ expr jpExpr }
+
+func (e jpNot) test(root, cur gjson.Result) bool {
+	return !e.expr.test(root, cur)
+}
+
+// jpExists tests that a query selects something or that a function such as
+// match() returns true
+type jpExists struct{ operand jpOperand }
+
+func (e jpExists) test(root, cur gjson.Result) bool {
+	nodes := e.operand.eval(root, cur)
+	if _, ok := e.operand.(*jpFunc); ok {
+		return len(nodes) == 1 && nodes[0].Type == gjson.True
+	}
+	return len(nodes) > 0
+}
+
+type jpCompare struct {
+	op          string
+	left, right jpOperand
+}
+
+func (e jpCompare) test(root, cur gjson.Result) bool {
+	l := single(e.left.eval(root, cur))
+	r := single(e.right.eval(root, cur))
+
+	switch e.op {
+	case "==":
+		return equal(l, r)
+	case "!=":
+		return !equal(l, r)
+	case "<":
+		return less(l, r)
+	case ">":
+		return less(r, l)
+	case "<=":
+		return less(l, r) || equal(l, r)
+	case ">=":
+		return less(r, l) || equal(l, r)
+	default:
+		return false
+	}
+}
+
+// single returns the only node of a singular query, or a missing value
+func single(nodes []gjson.Result) gjson.Result {
+	if len(nodes) != 1 {
+		return gjson.Result{}
+	}
+	return nodes[0]
+}
+
+func equal(a, b gjson.Result) bool {
+	if !a.Exists() || !b.Exists() {
+		return a.Exists() == b.Exists()
+	}
+
+	switch {
+	case a.Type != b.Type:
+		return false
+	case a.Type == gjson.Number:
+		return a.Float() == b.Float()
+	case a.Type == gjson.String:
+		return a.String() == b.String()
+	case a.Type == gjson.JSON:
+		return reflect.DeepEqual(a.Value(), b.Value())
+	default:
+		return true
+	}
+}
+
+func less(a, b gjson.Result) bool {
+	switch {
+	case !a.Exists() || !b.Exists() || a.Type != b.Type:
+		return false
+	case a.Type == gjson.Number:
+		return a.Float() < b.Float()
+	case a.Type == gjson.String:
+		return a.String() < b.String()
+	default:
+		return false
+	}
+}
+
+type jpLiteral struct{ value gjson.Result }
+
+func (l jpLiteral) eval(_, _ gjson.Result) []gjson.Result {
+	return []gjson.Result{l.value}
+}
+
+type jpQuery struct{ path *jsonPath }
+
+func (q jpQuery) eval(root, cur gjson.Result) []gjson.Result {
+	return q.path.eval(root, cur)
+}
+
+type jpFunc struct {
+	name string
+	args []jpOperand
+}
+
+var (
+	trueResult  = gjson.Parse("true")
+	falseResult = gjson.Parse("false")
+)
+
+func (f *jpFunc) eval(root, cur gjson.Result) []gjson.Result {
+	switch f.name {
+	case "length":
+		v := single(f.args[0].eval(root, cur))
+		switch {
+		case v.Type == gjson.String:
+			return []gjson.Result{gjson.Parse(strconv.Itoa(utf8.RuneCountInString(v.String())))}
+		case v.IsArray() || v.IsObject():
+			return []gjson.Result{gjson.Parse(strconv.Itoa(len(children(v))))}
+		default:
+			return nil
+		}
+	case "count":
+		return []gjson.Result{gjson.Parse(strconv.Itoa(len(f.args[0].eval(root, cur))))}
+	case "value":
+		v := single(f.args[0].eval(root, cur))
+		if !v.Exists() {
+			return nil
+		}
+		return []gjson.Result{v}
+	case "match", "search":
+		s := single(f.args[0].eval(root, cur))
+		re := single(f.args[1].eval(root, cur))
+		if s.Type != gjson.String || re.Type != gjson.String {
+			return []gjson.Result{falseResult}
+		}
+
+		expr := re.String()
+		if f.name == "match" {
+			expr = `^(?:` + expr + `)$`
+		}
+
+		compiled, err := compileRegexp(expr)
+		if err != nil || !compiled.MatchString(s.String()) {
+			return []gjson.Result{falseResult}
+		}
+		return []gjson.Result{trueResult}
+	default:
+		return nil
+	}
+}
+
+// jpParser is a recursive descent parser for RFC 9535 queries
+type jpParser struct {
+	src string
+	pos int
+}
+
+func (p *jpParser) parse() (*jsonPath, error) {
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with '$'")
+	}
+
+	path, err := p.parseSegments(false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if p.pos != len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+
+	return path, nil
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *jpParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jpParser) skipBlank() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n\r", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// parseSegments parses the segments following '$' or '@'
+func (p *jpParser) parseSegments(relative bool) (*jsonPath, error) {
+	path := &jsonPath{relative: relative}
+	for {
+		start := p.pos
+		p.skipBlank()
+
+		var seg jpSegment
+		switch {
+		case p.consume(".."):
+			seg.descendant = true
+			if p.peek() == '[' {
+				sels, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = sels
+			} else {
+				sel, err := p.parseShorthand()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = []jpSelector{sel}
+			}
+		case p.consume("."):
+			sel, err := p.parseShorthand()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = []jpSelector{sel}
+		case p.peek() == '[':
+			sels, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = sels
+		default:
+			p.pos = start
+			return path, nil
+		}
+
+		path.segments = append(path.segments, seg)
+	}
+}
+
+// parseShorthand parses the wildcard or member name after '.' or '..'
+func (p *jpParser) parseShorthand() (jpSelector, error) {
+	if p.consume("*") {
+		return jpSelector{kind: jpWildcard}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !(r == '_' || r >= 0x80 || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || (p.pos > start && r >= '0' && r <= '9')) {
+			break
+		}
+		p.pos += size
+	}
+
+	if p.pos == start {
+		return jpSelector{}, p.errorf("expected a member name")
+	}
+
+	return jpSelector{kind: jpName, name: p.src[start:p.pos]}, nil
+}
+
+// parseBracket parses a bracketed list of selectors
+func (p *jpParser) parseBracket() ([]jpSelector, error) {
+	p.consume("[")
+
+	var sels []jpSelector
+	for {
+		p.skipBlank()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+
+		p.skipBlank()
+		if p.consume("]") {
+			return sels, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		return jpSelector{kind: jpName, name: s}, nil
+	case c == '*':
+		p.pos++
+		return jpSelector{kind: jpWildcard}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		expr, err := p.parseOr()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		return jpSelector{kind: jpFilter, filter: expr}, nil
+	case c == '-' || c == ':' || c >= '0' && c <= '9':
+		return p.parseIndexOrSlice()
+	default:
+		return jpSelector{}, p.errorf("invalid selector")
+	}
+}
+
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var bounds [3]*int
+	for i := 0; i < 3; i++ {
+		p.skipBlank()
+		if c := p.peek(); c == '-' || c >= '0' && c <= '9' {
+			n, err := p.parseInt()
+			if err != nil {
+				return jpSelector{}, err
+			}
+			bounds[i] = &n
+		}
+
+		p.skipBlank()
+		if i == 2 || !p.consume(":") {
+			if i == 0 {
+				if bounds[0] == nil {
+					return jpSelector{}, p.errorf("expected an index")
+				}
+				return jpSelector{kind: jpIndex, index: *bounds[0]}, nil
+			}
+			break
+		}
+	}
+
+	return jpSelector{kind: jpSlice, slice: bounds}, nil
+}
+
+func (p *jpParser) parseInt() (int, error) {
+	start := p.pos
+	p.consume("-")
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, p.errorf("invalid integer %q", p.src[start:p.pos])
+	}
+
+	return n, nil
+}
+
+// parseString parses a single or double quoted string literal
+func (p *jpParser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c != '\\':
+			b.WriteByte(c)
+		case p.pos >= len(p.src):
+			return "", p.errorf("unterminated string")
+		default:
+			e := p.src[p.pos]
+			p.pos++
+			switch e {
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '/', '\\', '\'', '"':
+				b.WriteByte(e)
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				p.pos += 4
+				b.WriteRune(rune(r))
+			default:
+				return "", p.errorf("invalid escape '\\%c'", e)
+			}
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jpParser) parseOr() (jpExpr, error) {
+	var or jpOr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+
+		p.skipBlank()
+		if !p.consume("||") {
+			break
+		}
+		p.skipBlank()
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *jpParser) parseAnd() (jpExpr, error) {
+	var and jpAnd
+	for {
+		expr, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+
+		p.skipBlank()
+		if !p.consume("&&") {
+			break
+		}
+		p.skipBlank()
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+var jpCompareOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *jpParser) parseBasic() (jpExpr, error) {
+	if p.consume("!") {
+		p.skipBlank()
+		expr, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		return jpNot{expr}, nil
+	}
+
+	if p.consume("(") {
+		p.skipBlank()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		if !p.consume(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	for _, op := range jpCompareOps {
+		if !p.consume(op) {
+			continue
+		}
+
+		p.skipBlank()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if !comparable(left) || !comparable(right) {
+			return nil, p.errorf("only singular queries can be compared")
+		}
+
+		return jpCompare{op: op, left: left, right: right}, nil
+	}
+
+	if _, ok := left.(jpLiteral); ok {
+		return nil, p.errorf("a literal must be compared")
+	}
+
+	return jpExists{left}, nil
+}
+
+// comparable reports whether operand yields at most one value
+func comparable(operand jpOperand) bool {
+	q, ok := operand.(jpQuery)
+	return !ok || q.path.singular()
+}
+
+var jpNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?`)
+
+func (p *jpParser) parseOperand() (jpOperand, error) {
+	rest := p.src[p.pos:]
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		path, err := p.parseSegments(c == '@')
+		if err != nil {
+			return nil, err
+		}
+		return jpQuery{path}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jpLiteral{stringResult(s)}, nil
+	case jpNumber.MatchString(rest):
+		n := jpNumber.FindString(rest)
+		p.pos += len(n)
+		return jpLiteral{gjson.Parse(n)}, nil
+	case p.consume("true"):
+		return jpLiteral{trueResult}, nil
+	case p.consume("false"):
+		return jpLiteral{falseResult}, nil
+	case p.consume("null"):
+		return jpLiteral{gjson.Parse("null")}, nil
+	default:
+		return p.parseFunc()
+	}
+}
+
+var jpFuncArgs = map[string]int{
+	"length": 1,
+	"count":  1,
+	"value":  1,
+	"match":  2,
+	"search": 2,
+}
+
+func (p *jpParser) parseFunc() (jpOperand, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (p.src[p.pos] >= 'a' && p.src[p.pos] <= 'z' || p.src[p.pos] == '_') {
+		p.pos++
+	}
+
+	name := p.src[start:p.pos]
+	nargs, ok := jpFuncArgs[name]
+	if !ok || !p.consume("(") {
+		p.pos = start
+		return nil, p.errorf("expected a literal, a query or a function")
+	}
+
+	f := &jpFunc{name: name}
+	for {
+		p.skipBlank()
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+
+		p.skipBlank()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ')'")
+		}
+	}
+
+	if len(f.args) != nargs {
+		return nil, p.errorf("%s() takes %d arguments", name, nargs)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,135 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const storeJSON = `
+{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	}
+}`
+
+func TestGetJSONPath(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "$.store.book[0].title", expected: `"Sayings of the Century"`},
+		{query: "$['store']['bicycle'].color", expected: `"red"`},
+		{query: "$.store.book[-1].author", expected: `"J. R. R. Tolkien"`},
+		{query: "$.store.book[*].author", expected: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{query: "$..author", expected: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{query: "$.store..price", expected: `[8.95,12.99,8.99,22.99,399]`},
+		{query: "$..book[2].title", expected: `["Moby Dick"]`},
+		{query: "$..book[:2].price", expected: `[8.95,12.99]`},
+		{query: "$..book[::-2].price", expected: `[22.99,12.99]`},
+		{query: "$.store.book[0,2].price", expected: `[8.95,8.99]`},
+		{query: "$..book[?@.isbn].title", expected: `["Moby Dick","The Lord of the Rings"]`},
+		{query: "$.store.book[?(@.price < 10)].title", expected: `["Sayings of the Century","Moby Dick"]`},
+		{query: `$.store.book[?@.price > 10 && @.category == "fiction"].price`, expected: `[12.99,22.99]`},
+		{query: "$.store.book[?!(@.price < 20) || @.author == 'Nigel Rees'].title", expected: `["Sayings of the Century","The Lord of the Rings"]`},
+		{query: "$.store.book[?@.price < $.store.bicycle.price && length(@.title) > 15].title", expected: `["Sayings of the Century","The Lord of the Rings"]`},
+		{query: "$.store.book[?match(@.author, 'H.*')].title", expected: `["Moby Dick"]`},
+		{query: "$.store.book[?search(@.title, 'of')].title", expected: `["Sayings of the Century","Sword of Honour","The Lord of the Rings"]`},
+		{query: "$.store[?count(@.*) > 1].color", expected: `["red"]`},
+		{query: "$.store.missing", expected: ``},
+	}
+
+	for _, tt := range tests {
+		result, err := getJSONPath([]byte(storeJSON), tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+
+		if result.Raw != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.query, tt.expected, result.Raw)
+		}
+	}
+}
+
+func TestCompileJSONPathErrors(t *testing.T) {
+	queries := []string{
+		"$.store.",
+		"$.store[",
+		"$.store.book[?@.price <]",
+		"$.store.book[?@..price == 1]",
+		"$.store.book[?'a']",
+		"$.store.book[?unknown(@)]",
+		"$.store.book['unterminated]",
+		"$.store.book[0:1:2:3]",
+	}
+
+	for _, query := range queries {
+		if _, err := compileJSONPath(query); err == nil {
+			t.Errorf("%s: error should not be nil", query)
+		}
+	}
+}
+
+func TestUnmarshalJSONPath(t *testing.T) {
+	type Store struct {
+		Cheap   []string `njson:"$.store.book[?(@.price < 10)].title"`
+		Authors []string `njsonpath:"$..author"`
+		First   string   `njsonpath:"store.book[0].author,upper"`
+		Color   string   `njson:"store.bicycle.color"`
+		Count   int      `njson:"$.store.book.length"`
+	}
+
+	actual := Store{}
+
+	err := Unmarshal([]byte(storeJSON), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := Store{
+		Cheap:   []string{"Sayings of the Century", "Moby Dick"},
+		Authors: []string{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"},
+		First:   "NIGEL REES",
+		Color:   "red",
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	json := []byte(`{"name": {"first": "Mohamed"}, "items": [{"name": "a"}, {"name": "b"}]}`)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "name.first", expected: `"Mohamed"`},
+		{path: "/items/1/name", expected: `"b"`},
+		{path: "$.items[*].name", expected: `["a","b"]`},
+		{path: "missing", expected: ""},
+	}
+
+	for _, tt := range tests {
+		actual, err := Lookup(json, tt.path)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if actual.Raw != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.path, tt.expected, actual.Raw)
+		}
+	}
+
+	if _, err := Lookup(json, "/a~2"); err == nil {
+		t.Error("error should not be nil for an invalid path")
+	}
+}
@@ -0,0 +1,786 @@
+package njson
+
+import (
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	schemasMu sync.RWMutex
+	schemas   = map[reflect.Type]*JSONSchema{}
+)
+
+// maxRefDepth bounds the $ref chain followed for a single value, to stop
+// schemas that refer to themselves without consuming the document
+const maxRefDepth = 64
+
+// JSONSchema is a compiled JSON Schema (draft 2020-12) documents can be
+// checked against before they are decoded. It supports the validation
+// keywords, the applicators but "unevaluated*", and "$ref" to the schema
+// itself, e.g. "#/$defs/Friend"
+type JSONSchema struct {
+	root gjson.Result
+}
+
+// SchemaError reports a value that breaks a JSON Schema
+type SchemaError struct {
+	// Pointer is the JSON pointer of the value, "" for the whole document
+	Pointer string
+	// Keyword is the JSON pointer of the keyword broken in the schema, e.g.
+	// "/properties/age/maximum"
+	Keyword string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%q breaks %s: %s", e.Pointer, e.Keyword, e.Message)
+}
+
+// CompileSchema parses a JSON Schema and checks its patterns and
+// references
+func CompileSchema(schema []byte) (*JSONSchema, error) {
+	if !gjson.ValidBytes(schema) {
+		return nil, fmt.Errorf("invalid json schema: %v", string(schema))
+	}
+
+	s := &JSONSchema{root: gjson.ParseBytes(schema)}
+	if !s.root.IsObject() && s.root.Type != gjson.True && s.root.Type != gjson.False {
+		return nil, fmt.Errorf("json schema should be an object or a boolean, got %s", s.root.Raw)
+	}
+
+	if err := s.check(s.root); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// MustCompileSchema is like CompileSchema but panics on error
+func MustCompileSchema(schema []byte) *JSONSchema {
+	s, err := CompileSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// RegisterSchema makes Unmarshal check documents decoded into the struct
+// type typ against schema before setting any field, unless
+// UnmarshalOptions.Schema gives another one
+func RegisterSchema(typ reflect.Type, schema []byte) error {
+	s, err := CompileSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+
+	schemas[typ] = s
+	return nil
+}
+
+func registeredSchema(typ reflect.Type) *JSONSchema {
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+
+	return schemas[typ]
+}
+
+// Validate checks data against the schema and returns an Errors value
+// holding a *SchemaError for every violation
+func (s *JSONSchema) Validate(data []byte) error {
+	if !gjson.ValidBytes(data) {
+		return fmt.Errorf("invalid json: %v", string(data))
+	}
+
+	errs := s.violations(gjson.ParseBytes(data))
+	if len(errs) == 0 {
+		return nil
+	}
+
+	fieldErrs := make(Errors, len(errs))
+	for i, err := range errs {
+		fieldErrs[i] = &FieldError{Err: err}
+	}
+
+	return fieldErrs
+}
+
+func (s *JSONSchema) violations(doc gjson.Result) []*SchemaError {
+	v := &schemaValidator{root: s.root}
+	v.validate(s.root, "", doc, "")
+
+	return v.errs
+}
+
+// check walks a schema for patterns that don't compile and references that
+// can't be resolved
+func (s *JSONSchema) check(schema gjson.Result) (err error) {
+	schema.ForEach(func(key, value gjson.Result) bool {
+		switch {
+		case key.String() == "pattern" && value.Type == gjson.String:
+			_, err = compileRegexp(value.String())
+		case key.String() == "patternProperties" && value.IsObject():
+			value.ForEach(func(pattern, _ gjson.Result) bool {
+				_, err = compileRegexp(pattern.String())
+				return err == nil
+			})
+		case key.String() == "$ref" && value.Type == gjson.String:
+			_, err = s.resolve(value.String())
+		}
+
+		if err == nil && (value.IsObject() || value.IsArray()) {
+			err = s.check(value)
+		}
+		return err == nil
+	})
+
+	return err
+}
+
+// resolve returns the schema a "$ref" points to
+func (s *JSONSchema) resolve(ref string) (gjson.Result, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return gjson.Result{}, fmt.Errorf("unsupported $ref %q, only references within the schema are", ref)
+	}
+
+	pointer, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return gjson.Result{}, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return gjson.Result{}, fmt.Errorf("unsupported $ref %q, anchors are not supported", ref)
+	}
+
+	path, err := pointerPath(pointer)
+	if err != nil {
+		return gjson.Result{}, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+
+	target := s.root.Get(path)
+	if !target.Exists() {
+		return gjson.Result{}, fmt.Errorf("unresolved $ref %q", ref)
+	}
+
+	return target, nil
+}
+
+type schemaValidator struct {
+	root  gjson.Result
+	errs  []*SchemaError
+	depth int
+}
+
+func (v *schemaValidator) fail(at, keyword, format string, args ...interface{}) {
+	v.errs = append(v.errs, &SchemaError{Pointer: at, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid reports whether inst matches schema without recording errors
+func (v *schemaValidator) valid(schema gjson.Result, kw string, inst gjson.Result, at string) bool {
+	sub := &schemaValidator{root: v.root, depth: v.depth}
+	sub.validate(schema, kw, inst, at)
+
+	return len(sub.errs) == 0
+}
+
+// validate checks inst, found at the JSON pointer at, against schema, found
+// at the JSON pointer kw of the schema document
+func (v *schemaValidator) validate(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	switch schema.Type {
+	case gjson.True:
+		return
+	case gjson.False:
+		v.fail(at, kw, "no value is allowed")
+		return
+	}
+
+	if !schema.IsObject() {
+		return
+	}
+
+	if ref := schema.Get(`\$ref`); ref.Exists() {
+		target, err := (&JSONSchema{root: v.root}).resolve(ref.String())
+		switch {
+		case err != nil:
+			v.fail(at, kw+"/$ref", "%v", err)
+		case v.depth >= maxRefDepth:
+			v.fail(at, kw+"/$ref", "too many nested references")
+		default:
+			v.depth++
+			v.validate(target, ref.String()[1:], inst, at)
+			v.depth--
+		}
+	}
+
+	v.validateAny(schema, kw, inst, at)
+
+	switch {
+	case inst.Type == gjson.Number:
+		v.validateNumber(schema, kw, inst, at)
+	case inst.Type == gjson.String:
+		v.validateString(schema, kw, inst, at)
+	case inst.IsArray():
+		v.validateArray(schema, kw, inst, at)
+	case inst.IsObject():
+		v.validateObject(schema, kw, inst, at)
+	}
+
+	v.validateApplicators(schema, kw, inst, at)
+}
+
+// validateAny checks the keywords that apply to every type
+func (v *schemaValidator) validateAny(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	if typ := schema.Get("type"); typ.Exists() {
+		var types []string
+		if typ.IsArray() {
+			for _, t := range typ.Array() {
+				types = append(types, t.String())
+			}
+		} else {
+			types = []string{typ.String()}
+		}
+
+		ok := false
+		for _, t := range types {
+			ok = ok || hasSchemaType(inst, t)
+		}
+
+		if !ok {
+			v.fail(at, kw+"/type", "expected %s, got %s", strings.Join(types, " or "), schemaType(inst))
+		}
+	}
+
+	if enum := schema.Get("enum"); enum.IsArray() {
+		ok := false
+		for _, value := range enum.Array() {
+			ok = ok || jsonEqual(value, inst)
+		}
+
+		if !ok {
+			v.fail(at, kw+"/enum", "value %s is not one of %s", inst.Raw, enum.Raw)
+		}
+	}
+
+	if c := schema.Get("const"); c.Exists() && !jsonEqual(c, inst) {
+		v.fail(at, kw+"/const", "value %s is not %s", inst.Raw, c.Raw)
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	n, _ := new(big.Rat).SetString(inst.Raw)
+	if n == nil {
+		return
+	}
+
+	limits := []struct {
+		keyword string
+		breaks  func(cmp int) bool
+		message string
+	}{
+		{"minimum", func(cmp int) bool { return cmp < 0 }, "less than"},
+		{"maximum", func(cmp int) bool { return cmp > 0 }, "greater than"},
+		{"exclusiveMinimum", func(cmp int) bool { return cmp <= 0 }, "not greater than"},
+		{"exclusiveMaximum", func(cmp int) bool { return cmp >= 0 }, "not less than"},
+	}
+
+	for _, limit := range limits {
+		l := schema.Get(limit.keyword)
+		if l.Type != gjson.Number {
+			continue
+		}
+
+		if r, ok := new(big.Rat).SetString(l.Raw); ok && limit.breaks(n.Cmp(r)) {
+			v.fail(at, kw+"/"+limit.keyword, "value %s is %s %s", inst.Raw, limit.message, l.Raw)
+		}
+	}
+
+	if m := schema.Get("multipleOf"); m.Type == gjson.Number {
+		if r, ok := new(big.Rat).SetString(m.Raw); ok && r.Sign() > 0 {
+			if !new(big.Rat).Quo(n, r).IsInt() {
+				v.fail(at, kw+"/multipleOf", "value %s is not a multiple of %s", inst.Raw, m.Raw)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	s := inst.String()
+	n := utf8.RuneCountInString(s)
+
+	if min := schema.Get("minLength"); min.Exists() && n < int(min.Int()) {
+		v.fail(at, kw+"/minLength", "length %d is less than %d", n, min.Int())
+	}
+
+	if max := schema.Get("maxLength"); max.Exists() && n > int(max.Int()) {
+		v.fail(at, kw+"/maxLength", "length %d is greater than %d", n, max.Int())
+	}
+
+	if pattern := schema.Get("pattern"); pattern.Exists() {
+		if re, err := compileRegexp(pattern.String()); err == nil && !re.MatchString(s) {
+			v.fail(at, kw+"/pattern", "%q doesn't match %s", s, pattern.String())
+		}
+	}
+
+	if format := schema.Get("format"); format.Exists() && !hasFormat(s, format.String()) {
+		v.fail(at, kw+"/format", "%q is not a valid %s", s, format.String())
+	}
+}
+
+// hasFormat checks the formats whose meaning is unambiguous, others are
+// treated as annotations
+func hasFormat(s, format string) bool {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse("2006-01-02", s)
+	case "time":
+		_, err = time.Parse("15:04:05Z07:00", s)
+	case "email":
+		var addr *mail.Address
+		addr, err = mail.ParseAddress(s)
+		if err == nil && addr.Address != s {
+			return false
+		}
+	case "uri":
+		var u *url.URL
+		u, err = url.Parse(s)
+		if err == nil && !u.IsAbs() {
+			return false
+		}
+	}
+
+	return err == nil
+}
+
+func (v *schemaValidator) validateArray(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	items := inst.Array()
+
+	prefix := schema.Get("prefixItems").Array()
+	for i, item := range items {
+		if i < len(prefix) {
+			v.validate(prefix[i], fmt.Sprintf("%s/prefixItems/%d", kw, i), item, at+"/"+strconv.Itoa(i))
+		} else if s := schema.Get("items"); s.Exists() {
+			v.validate(s, kw+"/items", item, at+"/"+strconv.Itoa(i))
+		}
+	}
+
+	if min := schema.Get("minItems"); min.Exists() && len(items) < int(min.Int()) {
+		v.fail(at, kw+"/minItems", "%d items are less than %d", len(items), min.Int())
+	}
+
+	if max := schema.Get("maxItems"); max.Exists() && len(items) > int(max.Int()) {
+		v.fail(at, kw+"/maxItems", "%d items are more than %d", len(items), max.Int())
+	}
+
+	if schema.Get("uniqueItems").Bool() {
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if jsonEqual(items[i], items[j]) {
+					v.fail(at, kw+"/uniqueItems", "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+
+	if contains := schema.Get("contains"); contains.Exists() {
+		count := 0
+		for i, item := range items {
+			if v.valid(contains, kw+"/contains", item, at+"/"+strconv.Itoa(i)) {
+				count++
+			}
+		}
+
+		min := 1
+		if m := schema.Get("minContains"); m.Exists() {
+			min = int(m.Int())
+		}
+
+		if count < min {
+			v.fail(at, kw+"/contains", "%d items match, at least %d should", count, min)
+		}
+
+		if max := schema.Get("maxContains"); max.Exists() && count > int(max.Int()) {
+			v.fail(at, kw+"/maxContains", "%d items match, at most %d should", count, max.Int())
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	props := schema.Get("properties")
+	patterns := schema.Get("patternProperties")
+	additional := schema.Get("additionalProperties")
+	names := schema.Get("propertyNames")
+
+	count := 0
+	inst.ForEach(func(key, value gjson.Result) bool {
+		count++
+		k := key.String()
+		ptr := at + "/" + escapePointer(k)
+
+		if names.Exists() {
+			v.validate(names, kw+"/propertyNames", key, ptr)
+		}
+
+		matched := false
+		if prop := props.Get(tagpath.EscapeKey(k)); props.IsObject() && prop.Exists() {
+			matched = true
+			v.validate(prop, kw+"/properties/"+escapePointer(k), value, ptr)
+		}
+
+		patterns.ForEach(func(pattern, s gjson.Result) bool {
+			if re, err := compileRegexp(pattern.String()); err == nil && re.MatchString(k) {
+				matched = true
+				v.validate(s, kw+"/patternProperties/"+escapePointer(pattern.String()), value, ptr)
+			}
+			return true
+		})
+
+		if !matched && additional.Exists() {
+			v.validate(additional, kw+"/additionalProperties", value, ptr)
+		}
+		return true
+	})
+
+	for _, name := range schema.Get("required").Array() {
+		if !inst.Get(tagpath.EscapeKey(name.String())).Exists() {
+			v.fail(at+"/"+escapePointer(name.String()), kw+"/required", "missing required property")
+		}
+	}
+
+	schema.Get("dependentRequired").ForEach(func(key, required gjson.Result) bool {
+		if !inst.Get(tagpath.EscapeKey(key.String())).Exists() {
+			return true
+		}
+
+		for _, name := range required.Array() {
+			if !inst.Get(tagpath.EscapeKey(name.String())).Exists() {
+				v.fail(at+"/"+escapePointer(name.String()), kw+"/dependentRequired/"+escapePointer(key.String()),
+					"missing property required by %q", key.String())
+			}
+		}
+		return true
+	})
+
+	if min := schema.Get("minProperties"); min.Exists() && count < int(min.Int()) {
+		v.fail(at, kw+"/minProperties", "%d properties are less than %d", count, min.Int())
+	}
+
+	if max := schema.Get("maxProperties"); max.Exists() && count > int(max.Int()) {
+		v.fail(at, kw+"/maxProperties", "%d properties are more than %d", count, max.Int())
+	}
+}
+
+// validateApplicators checks the keywords combining subschemas
+func (v *schemaValidator) validateApplicators(schema gjson.Result, kw string, inst gjson.Result, at string) {
+	for i, s := range schema.Get("allOf").Array() {
+		v.validate(s, fmt.Sprintf("%s/allOf/%d", kw, i), inst, at)
+	}
+
+	if anyOf := schema.Get("anyOf"); anyOf.IsArray() {
+		ok := false
+		for i, s := range anyOf.Array() {
+			ok = ok || v.valid(s, fmt.Sprintf("%s/anyOf/%d", kw, i), inst, at)
+		}
+
+		if !ok {
+			v.fail(at, kw+"/anyOf", "value matches none of the schemas")
+		}
+	}
+
+	if oneOf := schema.Get("oneOf"); oneOf.IsArray() {
+		count := 0
+		for i, s := range oneOf.Array() {
+			if v.valid(s, fmt.Sprintf("%s/oneOf/%d", kw, i), inst, at) {
+				count++
+			}
+		}
+
+		if count != 1 {
+			v.fail(at, kw+"/oneOf", "value matches %d of the schemas instead of one", count)
+		}
+	}
+
+	if not := schema.Get("not"); not.Exists() && v.valid(not, kw+"/not", inst, at) {
+		v.fail(at, kw+"/not", "value matches a schema it must not")
+	}
+
+	if cond := schema.Get("if"); cond.Exists() {
+		if v.valid(cond, kw+"/if", inst, at) {
+			if then := schema.Get("then"); then.Exists() {
+				v.validate(then, kw+"/then", inst, at)
+			}
+		} else if els := schema.Get("else"); els.Exists() {
+			v.validate(els, kw+"/else", inst, at)
+		}
+	}
+
+	if inst.IsObject() {
+		schema.Get("dependentSchemas").ForEach(func(key, s gjson.Result) bool {
+			if inst.Get(tagpath.EscapeKey(key.String())).Exists() {
+				v.validate(s, kw+"/dependentSchemas/"+escapePointer(key.String()), inst, at)
+			}
+			return true
+		})
+	}
+}
+
+// hasSchemaType reports whether inst is of the JSON Schema type typ
+func hasSchemaType(inst gjson.Result, typ string) bool {
+	if typ == "integer" {
+		n, ok := new(big.Rat).SetString(inst.Raw)
+		return inst.Type == gjson.Number && ok && n.IsInt()
+	}
+
+	return schemaType(inst) == typ || typ == "number" && schemaType(inst) == "integer"
+}
+
+// schemaType returns the JSON Schema type of inst, "integer" for numbers
+// without a fractional part
+func schemaType(inst gjson.Result) string {
+	switch inst.Type {
+	case gjson.Null:
+		return "null"
+	case gjson.True, gjson.False:
+		return "boolean"
+	case gjson.String:
+		return "string"
+	case gjson.Number:
+		if n, ok := new(big.Rat).SetString(inst.Raw); ok && n.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		if inst.IsArray() {
+			return "array"
+		}
+		return "object"
+	}
+}
+
+// jsonEqual compares two JSON values the way JSON Schema does: numbers by
+// value and objects regardless of the order of their keys
+func jsonEqual(a, b gjson.Result) bool {
+	switch {
+	case a.Type == gjson.Number && b.Type == gjson.Number:
+		x, okx := new(big.Rat).SetString(a.Raw)
+		y, oky := new(big.Rat).SetString(b.Raw)
+		return okx && oky && x.Cmp(y) == 0
+	case a.IsArray() && b.IsArray():
+		x, y := a.Array(), b.Array()
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case a.IsObject() && b.IsObject():
+		x, y := a.Map(), b.Map()
+		if len(x) != len(y) {
+			return false
+		}
+		for k, value := range x {
+			other, ok := y[k]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case a.Type == gjson.JSON || b.Type == gjson.JSON:
+		return false
+	default:
+		return a.Type == b.Type && a.String() == b.String()
+	}
+}
+
+// escapePointer escapes a key as a JSON pointer token
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// checkSchema validates data against the schema given by o or registered
+// for typ, and maps each violation to the field of typ reading the value
+func (o UnmarshalOptions) checkSchema(data []byte, typ reflect.Type) error {
+	schema := o.Schema
+	if schema == nil {
+		schema = registeredSchema(typ)
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	violations := schema.violations(gjson.ParseBytes(data))
+	if len(violations) == 0 {
+		return nil
+	}
+
+	errs := make(Errors, len(violations))
+	for i, err := range violations {
+		f, _ := o.fieldAt(typ, pointerTokens(err.Pointer), fieldInfo{})
+		errs[i] = &FieldError{Field: f.name(), Path: f.path(), Err: err}
+	}
+
+	return errs
+}
+
+// pointerTokens splits a JSON pointer into its unescaped tokens
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens
+}
+
+// pointerKeys returns the gjson path of the pointer tokens
+func pointerKeys(tokens []string) string {
+	keys := make([]string, len(tokens))
+	for i, token := range tokens {
+		keys[i] = tagpath.EscapeKey(token)
+	}
+
+	return strings.Join(keys, ".")
+}
+
+// fieldAt returns the deepest field of the struct type typ decoded from the
+// value at the JSON pointer tokens, or from a value containing it
+func (o UnmarshalOptions) fieldAt(typ reflect.Type, tokens []string, parent fieldInfo) (fieldInfo, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return parent, false
+	}
+
+	var best fieldInfo
+	var bestType reflect.Type
+	matched := -1
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+
+		tag, opts, ok := o.fieldTag(sf)
+		if !ok || sf.PkgPath != "" || tagpath.IsJSONPath(tag) {
+			continue
+		}
+
+		path := tag
+		if isPointer(tag, opts) {
+			var err error
+			if path, err = pointerPath(tag); err != nil {
+				continue
+			}
+		}
+
+		n, at := matchTokens(path, tokens)
+		if n > matched {
+			matched = n
+			best = parent.child(sf.Name, pointerKeys(tokens[:n]), opts)
+			bestType = sf.Type
+
+			// the element an array query picked
+			if at >= 0 {
+				field := best
+				best = fieldInfo{parent: &field, kind: queryElem, index: at, opts: opts}
+				bestType = elemType(bestType)
+			}
+		}
+	}
+
+	if matched < 0 {
+		return parent, false
+	}
+
+	rest := tokens[matched:]
+	for len(rest) > 0 {
+		for bestType.Kind() == reflect.Ptr {
+			bestType = bestType.Elem()
+		}
+
+		switch bestType.Kind() {
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(rest[0])
+			if err != nil {
+				return best, true
+			}
+			// the element points to its holder, which reassigning best would overwrite
+			holder := best
+			best, bestType, rest = holder.elem(i), bestType.Elem(), rest[1:]
+		case reflect.Map:
+			holder := best
+			best, bestType, rest = holder.key(rest[0]), bestType.Elem(), rest[1:]
+		case reflect.Struct:
+			if f, ok := o.fieldAt(bestType, rest, best); ok {
+				return f, true
+			}
+			return best, true
+		default:
+			return best, true
+		}
+	}
+
+	return best, true
+}
+
+// matchTokens returns how many of the pointer tokens the gjson path
+// matches, -1 if it doesn't lead to them, and the index matched by its
+// first '#', -1 if none. A path leading into the pointer also matches, the
+// value found there holds the one the pointer designates
+func matchTokens(path string, tokens []string) (n, index int) {
+	index = -1
+	if path == "@this" {
+		return 0, index
+	}
+
+	comps, seps := tagpath.Split(path)
+	for i, comp := range comps {
+		if i == len(tokens) {
+			// the pointer designates a value containing the field's
+			return -1, -1
+		}
+
+		switch {
+		case seps[i] == '|':
+			return -1, -1
+		case comp == "#":
+			if !tagpath.IsIndex(tokens[i]) {
+				return -1, -1
+			}
+			if index < 0 {
+				index, _ = strconv.Atoi(tokens[i])
+			}
+		case tagpath.IsIndex(comp) || tagpath.IsPlainKey(comp):
+			if tagpath.UnescapeKey(comp) != tokens[i] {
+				return -1, -1
+			}
+		default:
+			return -1, -1
+		}
+	}
+
+	return len(comps), index
+}
@@ -0,0 +1,158 @@
+package njson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema := MustCompileSchema([]byte(`
+	{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[A-Z]"},
+			"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+			"price": {"type": "number", "multipleOf": 0.01},
+			"email": {"type": "string", "format": "email"},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "uniqueItems": true, "maxItems": 3},
+			"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+			"friend": {"$ref": "#/$defs/friend"},
+			"kind": {"oneOf": [{"const": "x"}, {"const": "y"}]},
+			"a/b": {"not": {"type": "null"}}
+		},
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"$defs": {
+			"friend": {
+				"type": "object",
+				"properties": {"first": {"type": "string"}, "friend": {"$ref": "#/$defs/friend"}},
+				"required": ["first"]
+			}
+		}
+	}`))
+
+	if err := schema.Validate([]byte(`{"name": "Tom", "age": 37, "price": 12.5, "tags": ["a"], "point": [1, 2.5],
+		"friend": {"first": "Dale", "friend": {"first": "Roger"}}, "kind": "x", "a/b": 1}`)); err != nil {
+		t.Error(err)
+	}
+
+	err := schema.Validate([]byte(`{"name": "t", "age": 150.0, "price": 1.005, "email": "tom", "tags": ["a", "c", "a", "b"],
+		"point": [1, 2, 3], "friend": {"friend": {"first": 1}}, "kind": "z", "a/b": null, "extra": true}`))
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var actual []string
+	for _, err := range errs {
+		var se *SchemaError
+		if !errors.As(err, &se) {
+			t.Fatalf("error should be a SchemaError, got %v", err)
+		}
+		actual = append(actual, se.Pointer+" "+se.Keyword)
+	}
+
+	expected := []string{
+		"/name /properties/name/minLength",
+		"/name /properties/name/pattern",
+		"/age /properties/age/exclusiveMaximum",
+		"/price /properties/price/multipleOf",
+		"/email /properties/email/format",
+		"/tags/1 /properties/tags/items/enum",
+		"/tags /properties/tags/maxItems",
+		"/tags /properties/tags/uniqueItems",
+		"/point/2 /properties/point/items",
+		"/friend/friend/first /$defs/friend/properties/first/type",
+		"/friend/first /$defs/friend/required",
+		"/kind /properties/kind/oneOf",
+		"/a~1b /properties/a~1b/not",
+		"/extra /additionalProperties",
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+}
+
+func TestCompileSchema(t *testing.T) {
+	tests := []string{
+		`{"type": "object",`,
+		`"object"`,
+		`{"properties": {"a": {"pattern": "("}}}`,
+		`{"patternProperties": {"(": {}}}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+	}
+
+	for _, schema := range tests {
+		if _, err := CompileSchema([]byte(schema)); err == nil {
+			t.Errorf("%s: error should not be nil", schema)
+		}
+	}
+
+	if _, err := CompileSchema([]byte(`true`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnmarshalSchema(t *testing.T) {
+	type Friend struct {
+		First string `njson:"first,required"`
+		Age   int    `njson:"age,min=0"`
+	}
+
+	type User struct {
+		Name    string   `njson:"name.first,required"`
+		Ages    []int    `njson:"friends.#.age"`
+		Friends []Friend `njson:"friends"`
+		Tags    []string `njson:"tags"`
+	}
+
+	schema, err := Schema(reflect.TypeOf(User{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterSchema(reflect.TypeOf(User{}), schema); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		schemasMu.Lock()
+		delete(schemas, reflect.TypeOf(User{}))
+		schemasMu.Unlock()
+	}()
+
+	json := `{"name": {"last": "Anderson"}, "friends": [{"first": "Dale", "age": 44}, {"age": -1}], "tags": ["a", 1]}`
+
+	actual := User{Name: "unchanged"}
+	err = Unmarshal([]byte(json), &actual)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+
+	expected := []string{"Name", "Ages[1]", "Friends[1].First", "Tags[1]"}
+	if diff := cmp.Diff(expected, fields); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+
+	if actual.Name != "unchanged" {
+		t.Error("no field should be set when the document breaks the schema")
+	}
+
+	// a schema given in the options takes precedence
+	opts := UnmarshalOptions{Schema: MustCompileSchema([]byte(`{"required": ["tags"]}`))}
+	if err := opts.Unmarshal([]byte(`{"name": {"first": "Tom"}}`), &actual); err == nil {
+		t.Error("error should not be nil for a document missing tags")
+	}
+}
@@ -0,0 +1,115 @@
+package njson
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+
+	"github.com/tidwall/gjson"
+)
+
+var nullTimeType = reflect.TypeOf(sql.NullTime{})
+
+// Nullable holds a value that may be missing from the document or null.
+// Set reports whether the path exists and Valid whether it holds a non null
+// value, so `"deleted_at": null` (Set, not Valid) can be told apart from a
+// missing "deleted_at" (neither Set nor Valid)
+type Nullable[T any] struct {
+	Value T
+	Valid bool
+	Set   bool
+}
+
+func (n *Nullable[T]) decodeNJSON(d *decodeState, result gjson.Result, f fieldInfo) {
+	var zero T
+	n.Value = zero
+	n.Set = result.Exists()
+	n.Valid = n.Set && result.Type != gjson.Null
+
+	if n.Valid {
+		d.setField(result, reflect.ValueOf(&n.Value).Elem(), f)
+	}
+}
+
+// UnmarshalJSON lets Nullable be used with encoding/json as well
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	n.Value = zero
+	n.Set = true
+	n.Valid = string(data) != "null"
+
+	if !n.Valid {
+		return nil
+	}
+
+	return json.Unmarshal(data, &n.Value)
+}
+
+// MarshalJSON encodes the value, or null if it is not valid
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.Value)
+}
+
+// fieldDecoder is implemented by types that decode themselves from a result,
+// including a missing one
+type fieldDecoder interface {
+	decodeNJSON(d *decodeState, result gjson.Result, f fieldInfo)
+}
+
+// decodeCustom decodes field with the type's own decoding, Nullable,
+// json.Unmarshaler or sql.Scanner, and reports whether it did so
+func (d *decodeState) decodeCustom(result gjson.Result, field reflect.Value, f fieldInfo) bool {
+	if !field.CanAddr() {
+		return false
+	}
+
+	switch x := field.Addr().Interface().(type) {
+	case fieldDecoder:
+		x.decodeNJSON(d, result, f)
+		return true
+	case json.Unmarshaler:
+		if field.Type() == timeType || !result.Exists() || hasNJSONTags(field.Type()) {
+			return false
+		}
+
+		unmarshalGeneric(result.Raw, field)
+		return true
+	case sql.Scanner:
+		if !result.Exists() {
+			return false
+		}
+
+		if err := x.Scan(scanValue(result, field.Type())); err != nil {
+			panic(err)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// scanValue converts result to the value passed to sql.Scanner.Scan
+func scanValue(result gjson.Result, typ reflect.Type) interface{} {
+	switch result.Type {
+	case gjson.Null:
+		return nil
+	case gjson.True, gjson.False:
+		return result.Bool()
+	case gjson.Number:
+		if i, err := json.Number(result.Raw).Int64(); err == nil {
+			return i
+		}
+		return result.Float()
+	case gjson.String:
+		if typ == nullTimeType {
+			return result.Time()
+		}
+		return result.String()
+	default:
+		return []byte(result.Raw)
+	}
+}
@@ -0,0 +1,93 @@
+package njson
+
+import (
+	"database/sql"
+	json2 "encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshalNull(t *testing.T) {
+	json := `
+	{
+		"name": null,
+		"age": null,
+		"email": "m@example.com",
+		"deleted_at": null,
+		"tags": null,
+		"labels": null,
+		"extra": null,
+		"nick": null,
+		"score": 12,
+		"born": "2021-01-11T23:56:51Z"
+	}`
+
+	type User struct {
+		Name      Nullable[string]  `njson:"name"`
+		Age       Nullable[int]     `njson:"age"`
+		Email     Nullable[string]  `njson:"email"`
+		Phone     Nullable[string]  `njson:"phone"`
+		DeletedAt *time.Time        `njson:"deleted_at"`
+		Tags      []string          `njson:"tags"`
+		Labels    map[string]string `njson:"labels"`
+		Extra     interface{}       `njson:"extra"`
+		Nick      sql.NullString    `njson:"nick"`
+		Score     sql.NullInt64     `njson:"score"`
+		Born      sql.NullTime      `njson:"born"`
+	}
+
+	actual := User{
+		DeletedAt: &time.Time{},
+		Tags:      []string{"old"},
+		Labels:    map[string]string{"old": "value"},
+		Extra:     "old",
+	}
+
+	err := UnmarshalOptions{Merge: true}.Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	born, _ := time.Parse(time.RFC3339, "2021-01-11T23:56:51Z")
+
+	expected := User{
+		Name:  Nullable[string]{Set: true},
+		Age:   Nullable[int]{Set: true},
+		Email: Nullable[string]{Value: "m@example.com", Set: true, Valid: true},
+		Score: sql.NullInt64{Int64: 12, Valid: true},
+		Born:  sql.NullTime{Time: born, Valid: true},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// legacyName has an UnmarshalJSON method of its own, njson still decodes it
+// from its tags
+type legacyName struct {
+	First string `njson:"name.first"`
+}
+
+func (n *legacyName) UnmarshalJSON(data []byte) error {
+	return json2.Unmarshal(data, &struct{ First *string }{&n.First})
+}
+
+func TestUnmarshalTaggedUnmarshaler(t *testing.T) {
+	type User struct {
+		In legacyName `njson:"in"`
+	}
+
+	actual := User{}
+
+	err := Unmarshal([]byte(`{"in": {"name": {"first": "x"}}}`), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(User{In: legacyName{First: "x"}}, actual); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,132 @@
+package njson
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// NumberParser converts the literal text of a JSON number, such as
+// "12345678901234567890.123", to a value of the type it is registered for
+type NumberParser func(literal string) (interface{}, error)
+
+var (
+	numberTypesMu sync.RWMutex
+	numberTypes   = map[reflect.Type]NumberParser{}
+)
+
+func init() {
+	RegisterNumberType(reflect.TypeOf(big.Int{}), func(s string) (interface{}, error) {
+		n, err := parseBigInt(s)
+		if err != nil {
+			return nil, err
+		}
+		return *n, nil
+	})
+	RegisterNumberType(reflect.TypeOf(&big.Int{}), func(s string) (interface{}, error) {
+		return parseBigInt(s)
+	})
+	RegisterNumberType(reflect.TypeOf(big.Float{}), func(s string) (interface{}, error) {
+		n, err := parseBigFloat(s)
+		if err != nil {
+			return nil, err
+		}
+		return *n, nil
+	})
+	RegisterNumberType(reflect.TypeOf(&big.Float{}), func(s string) (interface{}, error) {
+		return parseBigFloat(s)
+	})
+	RegisterNumberType(reflect.TypeOf(big.Rat{}), func(s string) (interface{}, error) {
+		n, err := parseBigRat(s)
+		if err != nil {
+			return nil, err
+		}
+		return *n, nil
+	})
+	RegisterNumberType(reflect.TypeOf(&big.Rat{}), func(s string) (interface{}, error) {
+		return parseBigRat(s)
+	})
+}
+
+// RegisterNumberType makes fields of type typ decode from the literal text
+// of a JSON number, or of a string holding one, without going through
+// float64. It is meant for decimal libraries, e.g.
+//
+//	njson.RegisterNumberType(reflect.TypeOf(decimal.Decimal{}), func(s string) (interface{}, error) {
+//		return decimal.NewFromString(s)
+//	})
+//
+// *big.Int, *big.Float and *big.Rat, and their non pointer forms, are
+// registered by default
+func RegisterNumberType(typ reflect.Type, parse NumberParser) {
+	numberTypesMu.Lock()
+	defer numberTypesMu.Unlock()
+
+	numberTypes[typ] = parse
+}
+
+func numberParser(typ reflect.Type) NumberParser {
+	numberTypesMu.RLock()
+	defer numberTypesMu.RUnlock()
+
+	return numberTypes[typ]
+}
+
+// decodeNumber sets field from a registered number type and reports
+// whether it did so
+func (d *decodeState) decodeNumber(result gjson.Result, field reflect.Value, f fieldInfo) bool {
+	parse := numberParser(field.Type())
+	if parse == nil || !result.Exists() || result.Type == gjson.Null {
+		return false
+	}
+
+	var literal string
+	switch {
+	case result.Type == gjson.Number:
+		literal = result.Raw
+	case result.Type == gjson.String && (!d.opts.Strict || f.opts.has("string")):
+		literal = result.String()
+	default:
+		panic(fmt.Errorf("cannot unmarshal %s %s into %v", result.Type, result.Raw, field.Type()))
+	}
+
+	v, err := parse(literal)
+	if err != nil {
+		panic(fmt.Errorf("invalid number %q for %v: %w", literal, field.Type(), err))
+	}
+
+	field.Set(reflect.ValueOf(v))
+	return true
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("not an integer")
+	}
+
+	return n, nil
+}
+
+func parseBigFloat(s string) (*big.Float, error) {
+	// use enough bits to keep every digit of the literal
+	prec := uint(len(s)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+
+	n, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	return n, err
+}
+
+func parseBigRat(s string) (*big.Rat, error) {
+	n, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("not a number")
+	}
+
+	return n, nil
+}
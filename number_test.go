@@ -0,0 +1,77 @@
+package njson
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type cents int64
+
+func TestUnmarshalBigNumbers(t *testing.T) {
+	json := `
+	{
+		"balance": 12345678901234567890.123,
+		"id": 123456789012345678901234567890,
+		"ratio": "1/3",
+		"quoted": "98765432109876543210",
+		"price": 12.34
+	}`
+
+	RegisterNumberType(reflect.TypeOf(cents(0)), func(s string) (interface{}, error) {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("not a number")
+		}
+		return cents(new(big.Rat).Mul(r, big.NewRat(100, 1)).Num().Int64()), nil
+	})
+
+	type Account struct {
+		Balance *big.Float `njson:"balance"`
+		ID      big.Int    `njson:"id"`
+		Ratio   *big.Rat   `njson:"ratio"`
+		Quoted  *big.Int   `njson:"quoted"`
+		Missing *big.Int   `njson:"missing"`
+		Price   cents      `njson:"price"`
+	}
+
+	actual := Account{}
+
+	err := Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := actual.Balance.Text('f', 3); s != "12345678901234567890.123" {
+		t.Errorf("balance should keep its precision, got %s", s)
+	}
+
+	if s := actual.ID.String(); s != "123456789012345678901234567890" {
+		t.Errorf("unexpected id %s", s)
+	}
+
+	if s := actual.Ratio.String(); s != "1/3" {
+		t.Errorf("unexpected ratio %s", s)
+	}
+
+	if s := actual.Quoted.String(); s != "98765432109876543210" {
+		t.Errorf("unexpected quoted %s", s)
+	}
+
+	if actual.Missing != nil {
+		t.Errorf("missing should be nil, got %s", actual.Missing)
+	}
+
+	if actual.Price != 1234 {
+		t.Errorf("price should be 1234, got %d", actual.Price)
+	}
+
+	type Strict struct {
+		Quoted *big.Int `njson:"quoted"`
+	}
+
+	if err := (UnmarshalOptions{Strict: true}).Unmarshal([]byte(json), &Strict{}); err == nil {
+		t.Error("error should not be nil")
+	}
+}
@@ -0,0 +1,97 @@
+package njson
+
+// UnmarshalOptions configures how data is decoded. The zero value gives the
+// behaviour of the package level Unmarshal
+type UnmarshalOptions struct {
+	// CollectErrors keeps decoding after a field fails to convert, fills every
+	// field it can and returns an Errors value listing all failures instead of
+	// stopping at the first one
+	CollectErrors bool
+
+	// ElementErrors is the policy for slice elements that fail to decode. A
+	// field can override it with the "elements" tag option, e.g.
+	// `njson:"items,elements=skip"` (fail, skip or zero)
+	ElementErrors ElementPolicy
+
+	// Skipped, if not nil, receives an entry for every slice element that was
+	// skipped or zeroed under ElementSkip or ElementZero
+	Skipped *[]ElementError
+
+	// PresentFields, if not nil, records for every tagged struct field
+	// whether its path exists in the document, keyed by the field's Go path,
+	// e.g. "Name.First"
+	PresentFields map[string]bool
+
+	// PresentPaths is like PresentFields, keyed by the field's njson path,
+	// e.g. "name.first"
+	PresentPaths map[string]bool
+
+	// Merge overlays the document on the existing value of v: fields whose
+	// path is missing are left untouched, nested structs and maps are merged
+	// rather than replaced
+	Merge bool
+
+	// AppendSlices, together with Merge, appends decoded elements to existing
+	// slices instead of replacing them
+	AppendSlices bool
+
+	// Strict only lets number and bool fields be set from JSON numbers and
+	// booleans that fit them. A string such as "12" is rejected unless the
+	// field has the ",string" tag option
+	Strict bool
+
+	// CallValidate calls Validate on every decoded struct implementing
+	// Validator, after its AfterUnmarshalNJSON hook
+	CallValidate bool
+
+	// CaseInsensitive matches each key of a path to the document's keys
+	// without regard to case, like encoding/json does. An exact match is
+	// always preferred
+	CaseInsensitive bool
+
+	// FuzzyKeys is like CaseInsensitive but also ignores '_', '-' and spaces,
+	// so "firstName" finds "first_name". Case-insensitive matches are
+	// preferred over fuzzy ones
+	FuzzyKeys bool
+
+	// TagName is the struct tag holding paths and options, "njson" if empty
+	TagName string
+
+	// FallbackTags are consulted in order for fields without a TagName tag
+	FallbackTags []string
+
+	// JSONTag decides how "json" tags are used, by default they take
+	// precedence over all other tags
+	JSONTag JSONTagMode
+
+	// Schema, if not nil, is checked against the whole document before any
+	// field is set, taking the place of a schema registered for the type
+	// with RegisterSchema. Every violation is reported, with the field
+	// reading the offending value
+	Schema *JSONSchema
+}
+
+// ElementPolicy decides what happens to a slice element that fails to decode
+type ElementPolicy int
+
+const (
+	// ElementFail reports the element like any other field error
+	ElementFail ElementPolicy = iota
+	// ElementSkip leaves the element out of the slice
+	ElementSkip
+	// ElementZero keeps the zero value at the element's index
+	ElementZero
+)
+
+// JSONTagMode decides how "json" struct tags are used
+type JSONTagMode int
+
+const (
+	// JSONTagPreferred uses a "json" tag, when present, over any other tag
+	JSONTagPreferred JSONTagMode = iota
+	// JSONTagFallback only uses a "json" tag for fields that have neither a
+	// TagName tag nor one of the FallbackTags
+	JSONTagFallback
+	// JSONTagIgnored never uses "json" tags
+	JSONTagIgnored
+)
@@ -1,22 +1,25 @@
 package njson
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/tidwall/gjson"
 )
 
-func parseStructureType(result gjson.Result, field reflect.Type) (v interface{}) {
+func (d *decodeState) parseStructureType(result gjson.Result, field reflect.Type, f fieldInfo) (v interface{}) {
 	switch field.Kind() {
 	case reflect.Slice:
-		v = unmarshalSlice(result.Array(), field)
+		v = d.unmarshalSlice(result.Array(), field, f)
 	case reflect.Map:
-		v = unmarshalMap(result.Raw, field)
+		v = d.unmarshalMap(result, field, f)
 	case reflect.Struct:
 		if field.String() == "time.Time" {
 			v = result.Time()
 		} else {
-			v = unmarshalStruct(result.Raw, field)
+			v = d.unmarshalStruct(result.Raw, field, f)
 		}
 	default:
 		v = nil
@@ -53,3 +56,66 @@ func parseDataType(result gjson.Result, typ string) (v interface{}) {
 
 	return
 }
+
+// parseQuoted sets a number, bool or string field from a JSON string holding
+// its literal, as requested by the ",string" tag option
+func parseQuoted(result gjson.Result, field reflect.Value) {
+	if result.Type != gjson.String {
+		panic(fmt.Errorf("invalid use of ,string with %s value for %v", result.Type, field.Type()))
+	}
+
+	s := result.String()
+	var err error
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, field.Type().Bits())
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, field.Type().Bits())
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(s, field.Type().Bits())
+		field.SetFloat(n)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		field.SetBool(b)
+	case reflect.String:
+		var str string
+		err = json.Unmarshal([]byte(s), &str)
+		field.SetString(str)
+	default:
+		err = fmt.Errorf("invalid use of ,string with %v", field.Type())
+	}
+
+	if err != nil {
+		panic(fmt.Errorf("invalid ,string value %q for %v: %w", s, field.Type(), err))
+	}
+}
+
+// checkStrict makes sure a number or bool field is given a value of the
+// same JSON type, instead of relying on gjson's loose conversion
+func checkStrict(result gjson.Result, typ reflect.Type) {
+	var ok bool
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := strconv.ParseInt(result.Raw, 10, typ.Bits())
+		ok = result.Type == gjson.Number && err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err := strconv.ParseUint(result.Raw, 10, typ.Bits())
+		ok = result.Type == gjson.Number && err == nil
+	case reflect.Float32, reflect.Float64:
+		ok = result.Type == gjson.Number
+	case reflect.Bool:
+		ok = result.Type == gjson.True || result.Type == gjson.False
+	default:
+		ok = true
+	}
+
+	if !ok {
+		panic(fmt.Errorf("cannot unmarshal %s %s into %v", result.Type, result.Raw, typ))
+	}
+}
@@ -0,0 +1,212 @@
+package njson
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+	"github.com/tidwall/gjson"
+)
+
+// getKeys looks path up in data, comparing each plain key component with
+// each of matchers in turn when there is no exact match. After '#' the keys
+// are resolved in every element on its own, as elements may spell them
+// differently. Resolution stops at the first component it can't follow,
+// such as a query or a modifier, leaving the rest of the path as written
+func getKeys(data []byte, path string, matchers ...func(key, want string) bool) gjson.Result {
+	comps, seps := tagpath.Split(path)
+	cur := gjson.ParseBytes(data)
+
+	for i, comp := range comps {
+		if seps[i] == '|' || !cur.IsObject() || !tagpath.IsPlainKey(comp) {
+			if cur.IsArray() && tagpath.IsIndex(comp) {
+				cur = cur.Get(comp)
+				continue
+			}
+
+			if cur.IsArray() && comp == "#" && i+1 < len(comps) && seps[i+1] == '.' {
+				return getElems(cur, comps[i+1:], seps[i+1:], matchers)
+			}
+			break
+		}
+
+		if next := cur.Get(comp); next.Exists() {
+			cur = next
+			continue
+		}
+
+		key, ok := matchKey(cur, tagpath.UnescapeKey(comp), matchers)
+		if !ok {
+			break
+		}
+		comps[i] = tagpath.EscapeKey(key)
+		cur = member(cur, key)
+	}
+
+	return gjson.GetBytes(data, tagpath.Join(comps, seps))
+}
+
+// getElems applies the path components following '#' to every element of
+// array the way gjson does: up to the first '|' each element is looked up
+// on its own, the rest of the path applies to the resulting array
+func getElems(array gjson.Result, comps []string, seps []byte, matchers []func(key, want string) bool) gjson.Result {
+	end := len(comps)
+	for i := 1; i < len(comps); i++ {
+		if seps[i] == '|' {
+			end = i
+			break
+		}
+	}
+
+	path := tagpath.Join(comps[:end], append([]byte{0}, seps[1:end]...))
+	var elems []gjson.Result
+	for _, elem := range array.Array() {
+		if value := getKeys([]byte(elem.Raw), path, matchers...); value.Exists() {
+			elems = append(elems, value)
+		}
+	}
+
+	result := arrayResult(elems)
+	if end < len(comps) {
+		return result.Get(tagpath.Join(comps[end:], append([]byte{0}, seps[end+1:]...)))
+	}
+
+	return result
+}
+
+// matchKey returns the first key of the object obj that one of matchers,
+// tried in turn, finds equal to want
+func matchKey(obj gjson.Result, want string, matchers []func(key, want string) bool) (string, bool) {
+	for _, match := range matchers {
+		var found string
+		ok := false
+		obj.ForEach(func(key, _ gjson.Result) bool {
+			if match(key.String(), want) {
+				found, ok = key.String(), true
+			}
+			return !ok
+		})
+
+		if ok {
+			return found, true
+		}
+	}
+
+	return "", false
+}
+
+// foldKey matches keys that only differ in case
+func foldKey(key, want string) bool {
+	return strings.EqualFold(key, want)
+}
+
+// fuzzyKey matches keys that only differ in case and word separators, so
+// "first_name", "firstName", "first-name" and "FirstName" are all equal
+func fuzzyKey(key, want string) bool {
+	return normalizeKey(key) == normalizeKey(want)
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(key))
+}
+
+// checkPath reports syntax errors in a gjson path: unbalanced brackets or
+// quotes, dangling escapes, empty components, malformed array queries and
+// unknown modifiers
+func checkPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty path")
+	}
+
+	var stack []byte
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\':
+			if i+1 == len(path) {
+				return fmt.Errorf("invalid path %q: dangling escape", path)
+			}
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[' || c == '{' || c == '(':
+			stack = append(stack, c)
+		case c == ']' || c == '}' || c == ')':
+			open := map[byte]byte{']': '[', '}': '{', ')': '('}[c]
+			if len(stack) == 0 || stack[len(stack)-1] != open {
+				return fmt.Errorf("invalid path %q: unbalanced %q at offset %d", path, c, i)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if quoted {
+		return fmt.Errorf("invalid path %q: unterminated string", path)
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("invalid path %q: unclosed %q", path, stack[len(stack)-1])
+	}
+
+	comps, _ := tagpath.Split(path)
+	for _, comp := range comps {
+		switch {
+		case comp == "":
+			return fmt.Errorf("invalid path %q: empty component", path)
+		case comp[0] == '#':
+			if comp != "#" && !(strings.HasPrefix(comp, "#(") && (strings.HasSuffix(comp, ")") || strings.HasSuffix(comp, ")#"))) {
+				return fmt.Errorf("invalid path %q: malformed array query %q", path, comp)
+			}
+		case comp[0] == '@':
+			name := comp[1:]
+			if i := strings.IndexByte(name, ':'); i >= 0 {
+				name = name[:i]
+			}
+			if !gjson.ModifierExists(name, nil) {
+				return fmt.Errorf("invalid path %q: unknown modifier @%s", path, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// pathShape tells what kind of value a path gives regardless of the
+// document: a single value, the number of elements of an array ("a.#") or
+// an array of values ("a.#.b", "a.#(b>1)#", non singular JSONPath queries)
+type pathShape int
+
+const (
+	shapeValue pathShape = iota
+	shapeCount
+	shapeArray
+)
+
+func shapeOf(path string) pathShape {
+	if tagpath.IsJSONPath(path) {
+		if p, err := compileJSONPath(path); err == nil && !p.singular() {
+			return shapeArray
+		}
+		return shapeValue
+	}
+
+	comps, seps := tagpath.Split(path)
+	shape := shapeValue
+	for i, comp := range comps {
+		switch {
+		case seps[i] == '|':
+			// a pipe applies the rest of the path to the result as a whole
+			if shape == shapeCount {
+				shape = shapeValue
+			}
+		case comp == "#" && i == len(comps)-1:
+			if shape != shapeArray {
+				shape = shapeCount
+			}
+		case comp == "#" || strings.HasPrefix(comp, "#(") && strings.HasSuffix(comp, ")#"):
+			shape = shapeArray
+		}
+	}
+
+	return shape
+}
@@ -0,0 +1,116 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshalCaseInsensitive(t *testing.T) {
+	json := `
+	{
+		"Name": {"First": "Mohamed", "last_name": "Shapan"},
+		"AGE": 26,
+		"age": 27,
+		"fav.movie": "Deer Hunter",
+		"Friends": [{"First_Name": "Asma"}]
+	}`
+
+	type User struct {
+		First    string   `njson:"name.first"`
+		Last     string   `njson:"name.lastName"`
+		Age      int      `json:"age"`
+		Movie    string   `njson:"FAV\\.MOVIE"`
+		Friends  []string `njson:"friends.#.firstName"`
+		Friend   string   `njson:"friends.0.first-name"`
+		Children []string `njson:"children"`
+	}
+
+	t.Run("case insensitive", func(t *testing.T) {
+		actual := User{}
+
+		err := UnmarshalOptions{CaseInsensitive: true}.Unmarshal([]byte(json), &actual)
+		if err != nil {
+			t.Error(err)
+		}
+
+		expected := User{
+			First:    "Mohamed",
+			Age:      27,
+			Movie:    "Deer Hunter",
+			Friends:  []string{},
+			Children: []string{},
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("fuzzy", func(t *testing.T) {
+		actual := User{}
+
+		err := UnmarshalOptions{FuzzyKeys: true}.Unmarshal([]byte(json), &actual)
+		if err != nil {
+			t.Error(err)
+		}
+
+		expected := User{
+			First:    "Mohamed",
+			Last:     "Shapan",
+			Age:      27,
+			Movie:    "Deer Hunter",
+			Friends:  []string{"Asma"},
+			Friend:   "Asma",
+			Children: []string{},
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestUnmarshalCaseInsensitiveElements(t *testing.T) {
+	json := `
+	{
+		"friends": [
+			{"Name": "a", "Nets": ["ig"]},
+			{"name": "b", "nets": ["fb", "tw"]},
+			{"NAME": "c"},
+			{"age": 3}
+		]
+	}`
+
+	type User struct {
+		Names []string `njson:"friends.#.name"`
+		Nets  []int    `njson:"friends.#.nets.#"`
+		First []string `njson:"friends.#.nets|0"`
+	}
+
+	actual := User{}
+
+	err := UnmarshalOptions{CaseInsensitive: true}.Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := User{
+		Names: []string{"a", "b", "c"},
+		Nets:  []int{1, 2},
+		First: []string{"ig"},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	projected, err := UnmarshalOptions{CaseInsensitive: true}.Project([]byte(json), User{})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(`{"friends":[{"Name":"a","Nets":["ig"]},{"name":"b","nets":["fb","tw"]},{"NAME":"c"},{}]}`, string(projected)); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,48 @@
+package njson
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+)
+
+// isPointer reports whether a njson tag path is a JSON Pointer (RFC 6901),
+// either because it starts with '/' or because of the "pointer" option
+func isPointer(path string, opts tagOptions) bool {
+	return tagpath.IsPointer(path) || opts.has("pointer")
+}
+
+// pointerPath translates a JSON Pointer such as "/items/0/a~1b" to the
+// equivalent gjson path "items.0.a/b"
+func pointerPath(pointer string) (string, error) {
+	if pointer == "" {
+		return "@this", nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("invalid json pointer %q: must start with '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return "", fmt.Errorf("invalid json pointer %q: bad escape in %q", pointer, token)
+			}
+		}
+
+		switch token {
+		case "":
+			return "", fmt.Errorf("invalid json pointer %q: empty keys are not supported", pointer)
+		case "-":
+			return "", fmt.Errorf("invalid json pointer %q: '-' doesn't refer to an existing element", pointer)
+		}
+
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+		tokens[i] = tagpath.EscapeKey(token)
+	}
+
+	return strings.Join(tokens, "."), nil
+}
@@ -0,0 +1,85 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPointerPath(t *testing.T) {
+	tests := []struct {
+		pointer string
+		path    string
+		err     bool
+	}{
+		{pointer: "", path: "@this"},
+		{pointer: "/items/0/name", path: "items.0.name"},
+		{pointer: "/a~1b/m~0n", path: `a\/b.m\~n`},
+		{pointer: "/fav.movie", path: `fav\.movie`},
+		{pointer: "/a~2", err: true},
+		{pointer: "/items/-", err: true},
+		{pointer: "items", err: true},
+	}
+
+	for _, tt := range tests {
+		path, err := pointerPath(tt.pointer)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: error should not be nil", tt.pointer)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", tt.pointer, err)
+		}
+
+		if path != tt.path {
+			t.Errorf("%s: path should be %q, got %q", tt.pointer, tt.path, path)
+		}
+	}
+}
+
+func TestUnmarshalPointer(t *testing.T) {
+	json := `
+	{
+		"items": [{"name": "first"}, {"name": "second"}],
+		"paths": {"/api/v1": {"get": "list"}},
+		"fav.movie": "Deer Hunter"
+	}`
+
+	type Doc struct {
+		Name   string   `njson:"/items/1/name"`
+		Get    string   `njson:"/paths/~1api~1v1/get"`
+		Movie  string   `njson:"/fav.movie"`
+		Option string   `njson:"/items/0/name,pointer,upper"`
+		Names  []string `njson:"items.#.name"`
+	}
+
+	actual := Doc{}
+
+	err := Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := Doc{
+		Name:   "second",
+		Get:    "list",
+		Movie:  "Deer Hunter",
+		Option: "FIRST",
+		Names:  []string{"first", "second"},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	type Invalid struct {
+		Name string `njson:"/items/-"`
+	}
+
+	if err := Unmarshal([]byte(json), &Invalid{}); err == nil {
+		t.Error("error should not be nil")
+	}
+}
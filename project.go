@@ -0,0 +1,327 @@
+package njson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+	"github.com/tidwall/gjson"
+)
+
+// Project returns the parts of data the struct type of v reads: a JSON
+// document keeping only the subtrees its tags refer to, with their nesting,
+// object key order and array indices preserved. Elements of an array that
+// aren't read are replaced with null so later indices still match. v is a
+// struct or a pointer to one, it is never modified and may be a nil pointer.
+//
+// Unmarshal decodes the projection into the same values as data itself
+func Project(data []byte, v interface{}) ([]byte, error) {
+	return UnmarshalOptions{}.Project(data, v)
+}
+
+// Project is like the package level Project, using the tags and key
+// matching configured by o
+func (o UnmarshalOptions) Project(data []byte, v interface{}) ([]byte, error) {
+	if !gjson.ValidBytes(data) {
+		return nil, fmt.Errorf("invalid json: %v", string(data))
+	}
+
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't project to invalid type %v", reflect.TypeOf(v))
+	}
+
+	p := &projector{d: &decodeState{opts: o}}
+	root := &projection{value: gjson.ParseBytes(data)}
+	if err := p.projectStruct(root, typ); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	root.write(&b)
+
+	return b.Bytes(), nil
+}
+
+// projection is a node of the document being projected. It is kept whole
+// or reduced to the members and elements that were reached
+type projection struct {
+	value gjson.Result
+	all   bool
+
+	members map[string]*projection
+	elems   map[int]*projection
+	// length keeps every element of an array, as null when not reached,
+	// for paths that count or iterate over it
+	length bool
+}
+
+// member returns the node of the object member key, nil if there is none
+func (n *projection) member(key string) *projection {
+	value := member(n.value, key)
+	if !value.Exists() {
+		return nil
+	}
+
+	if c, ok := n.members[key]; ok {
+		return c
+	}
+
+	if n.members == nil {
+		n.members = map[string]*projection{}
+	}
+	c := &projection{value: value}
+	n.members[key] = c
+
+	return c
+}
+
+// elem returns the node of the i-th array element, nil if there is none
+func (n *projection) elem(i int) *projection {
+	if !n.value.IsArray() {
+		return nil
+	}
+
+	elems := n.value.Array()
+	if i < 0 || i >= len(elems) {
+		return nil
+	}
+
+	if c, ok := n.elems[i]; ok {
+		return c
+	}
+
+	if n.elems == nil {
+		n.elems = map[int]*projection{}
+	}
+	c := &projection{value: elems[i]}
+	n.elems[i] = c
+
+	return c
+}
+
+func (n *projection) write(b *bytes.Buffer) {
+	switch {
+	case n.all || n.value.Type != gjson.JSON:
+		// the raw value is valid, compacting it can't fail
+		_ = json.Compact(b, []byte(n.value.Raw))
+	case n.value.IsObject():
+		b.WriteByte('{')
+		written := map[string]bool{}
+		n.value.ForEach(func(key, _ gjson.Result) bool {
+			c, ok := n.members[key.String()]
+			if !ok || written[key.String()] {
+				return true
+			}
+			written[key.String()] = true
+
+			if len(written) > 1 {
+				b.WriteByte(',')
+			}
+			b.WriteString(key.Raw)
+			b.WriteByte(':')
+			c.write(b)
+			return true
+		})
+		b.WriteByte('}')
+	default:
+		size := 0
+		if n.length {
+			size = len(n.value.Array())
+		}
+		for i := range n.elems {
+			if i >= size {
+				size = i + 1
+			}
+		}
+
+		b.WriteByte('[')
+		for i := 0; i < size; i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if c, ok := n.elems[i]; ok {
+				c.write(b)
+			} else {
+				b.WriteString("null")
+			}
+		}
+		b.WriteByte(']')
+	}
+}
+
+// projector marks the nodes a struct type reads, following the same tags
+// and key matching as decoding does
+type projector struct {
+	d *decodeState
+}
+
+func (p *projector) projectStruct(n *projection, typ reflect.Type) error {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+
+		tag, opts, ok := p.d.opts.fieldTag(sf)
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+
+		if isPointer(tag, opts) {
+			path, err := pointerPath(tag)
+			if err != nil {
+				return &FieldError{Field: sf.Name, Path: tag, Err: err}
+			}
+			tag = path
+		}
+
+		if tagpath.IsJSONPath(tag) {
+			jp, err := compileJSONPath(tag)
+			if err != nil {
+				return &FieldError{Field: sf.Name, Path: tag, Err: err}
+			}
+			p.projectJSONPath(n, jp, sf.Type, opts)
+			continue
+		}
+
+		comps, seps := tagpath.Split(tag)
+		p.projectPath(n, comps, seps, sf.Type, opts)
+	}
+
+	return nil
+}
+
+// projectPath marks what the gjson path comps reads from n. Components
+// other than keys, indices and '#', such as queries and modifiers, keep the
+// node they apply to whole
+func (p *projector) projectPath(n *projection, comps []string, seps []byte, typ reflect.Type, opts tagOptions) {
+	for i, comp := range comps {
+		if n == nil || n.all {
+			return
+		}
+
+		switch {
+		case seps[i] == '|':
+			n.all = true
+			return
+		case comp == "#":
+			if !n.value.IsArray() {
+				return
+			}
+
+			n.length = true
+			if i == len(comps)-1 {
+				return
+			}
+
+			for j := range n.value.Array() {
+				p.projectPath(n.elem(j), comps[i+1:], seps[i+1:], elemType(typ), opts)
+			}
+			return
+		case n.value.IsArray() && tagpath.IsIndex(comp):
+			index, _ := strconv.Atoi(comp)
+			n = n.elem(index)
+		case n.value.IsObject() && tagpath.IsPlainKey(comp):
+			n = p.member(n, tagpath.UnescapeKey(comp))
+		default:
+			n.all = true
+			return
+		}
+	}
+
+	if n != nil {
+		p.projectValue(n, typ, opts)
+	}
+}
+
+// member returns the node of the object member key, matching keys the way
+// decoding does when there is no exact match
+func (p *projector) member(n *projection, key string) *projection {
+	if c := n.member(key); c != nil || !(p.d.opts.CaseInsensitive || p.d.opts.FuzzyKeys) {
+		return c
+	}
+
+	if match, ok := matchKey(n.value, key, p.d.keyMatchers()); ok {
+		return n.member(match)
+	}
+
+	return nil
+}
+
+// projectJSONPath marks what a JSONPath query reads from n. The leading
+// member and index selectors are followed, the node reached by them is kept
+// whole from the first selector that may pick several nodes
+func (p *projector) projectJSONPath(n *projection, jp *jsonPath, typ reflect.Type, opts tagOptions) {
+	for _, seg := range jp.segments {
+		if n == nil || n.all {
+			return
+		}
+
+		if seg.descendant || len(seg.selectors) != 1 {
+			n.all = true
+			return
+		}
+
+		switch sel := seg.selectors[0]; sel.kind {
+		case jpName:
+			n = n.member(sel.name)
+		case jpIndex:
+			index := sel.index
+			if index < 0 && n.value.IsArray() {
+				index += len(n.value.Array())
+			}
+			n = n.elem(index)
+		default:
+			n.all = true
+			return
+		}
+	}
+
+	if n != nil {
+		p.projectValue(n, typ, opts)
+	}
+}
+
+// projectValue marks what decoding a field of type typ reads from n.
+// Structs decoded field by field, and slices of them, only keep what their
+// own tags read, any other value is kept whole
+func (p *projector) projectValue(n *projection, typ reflect.Type, opts tagOptions) {
+	for _, opt := range opts {
+		if transform(opt.name) != nil {
+			n.all = true
+			return
+		}
+	}
+
+	typ = structBehind(typ)
+
+	switch {
+	case decodesFields(typ) && n.value.IsObject():
+		// a nested struct with bad tags fails to decode anyway
+		if err := p.projectStruct(n, typ); err != nil {
+			n.all = true
+		}
+	case typ.Kind() == reflect.Slice && decodesFields(structBehind(typ.Elem())) && n.value.IsArray():
+		n.length = true
+		for i := range n.value.Array() {
+			p.projectValue(n.elem(i), typ.Elem(), opts)
+		}
+	default:
+		n.all = true
+	}
+}
+
+// structBehind returns the struct typ points to if njson decodes it field by
+// field, typ otherwise
+func structBehind(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr && decodesFields(typ.Elem()) {
+		return typ.Elem()
+	}
+
+	return typ
+}
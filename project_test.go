@@ -0,0 +1,137 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProject(t *testing.T) {
+	type Friend struct {
+		First string `njson:"first"`
+		Age   int    `njson:"age"`
+	}
+
+	type User struct {
+		Name    string   `njson:"name.first"`
+		Count   int      `njson:"friends.#"`
+		Ages    []int    `njson:"friends.#.age"`
+		Best    Friend   `njson:"friends.1"`
+		Friends []Friend `njson:"$.children"`
+		Item    string   `njson:"/items/2/name"`
+		Tags    []string `njson:"tags,split=,"`
+		Movie   string   `json:"fav.movie"`
+		Older   []string `njson:"friends.#(age>45)#.last"`
+	}
+
+	data := []byte(`{
+		"name": {"first": "Tom", "last": "Anderson"},
+		"age": 37,
+		"children": [{"first": "Sara", "age": 7, "last": "A"}, {"first": "Alex"}],
+		"fav.movie": "Deer Hunter",
+		"tags": "a,b",
+		"items": [{"name": "x", "id": 1}, {"name": "y"}, {"name": "z", "id": 3}],
+		"friends": [
+			{"first": "Dale", "last": "Murphy", "age": 44},
+			{"first": "Roger", "last": "Craig", "age": 68, "nets": ["fb"]},
+			{"last": "Murphy"}
+		]
+	}`)
+
+	actual, err := Project(data, (*User)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"name":{"first":"Tom"},"children":[{"first":"Sara","age":7},{"first":"Alex"}],"fav.movie":"Deer Hunter","tags":"a,b","items":[null,null,{"name":"z"}],"friends":[{"first":"Dale","last":"Murphy","age":44},{"first":"Roger","last":"Craig","age":68,"nets":["fb"]},{"last":"Murphy"}]}`
+	if diff := cmp.Diff(expected, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+
+	var want, got User
+	if err := Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal(actual, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestProjectElements(t *testing.T) {
+	type Friend struct {
+		First string `njson:"first"`
+	}
+
+	type User struct {
+		Count int      `njson:"friends.#"`
+		Ages  []int    `njson:"friends.#.age"`
+		First string   `njson:"friends.1.first"`
+		Names []Friend `njson:"others"`
+	}
+
+	data := []byte(`{"friends": [{"first": "Dale", "age": 44}, {"first": "Roger"}, {"age": 3}], "others": [{"first": "Jane", "last": "Doe"}, null]}`)
+
+	actual, err := Project(data, User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"friends":[{"age":44},{"first":"Roger"},{"age":3}],"others":[{"first":"Jane"},null]}`
+	if diff := cmp.Diff(expected, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+
+	var want, got User
+	if err := Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal(actual, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestProjectOptions(t *testing.T) {
+	type User struct {
+		Name string `njson:"Name.First"`
+	}
+
+	data := []byte(`{"name": {"first": "Tom", "last": "Anderson"}, "age": 37}`)
+
+	actual, err := UnmarshalOptions{CaseInsensitive: true}.Project(data, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(`{"name":{"first":"Tom"}}`, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestProjectErrors(t *testing.T) {
+	type BadPointer struct {
+		Name string `njson:"/name/~2"`
+	}
+
+	tests := []struct {
+		name string
+		data string
+		v    interface{}
+	}{
+		{name: "invalid json", data: `{"name":`, v: &BadPointer{}},
+		{name: "not a struct", data: `{}`, v: "name"},
+		{name: "nil", data: `{}`, v: nil},
+		{name: "bad pointer", data: `{"name": "Tom"}`, v: BadPointer{}},
+	}
+
+	for _, test := range tests {
+		if _, err := Project([]byte(test.data), test.v); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
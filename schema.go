@@ -0,0 +1,469 @@
+package njson
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	fieldDecoderType = reflect.TypeOf((*fieldDecoder)(nil)).Elem()
+	unmarshalerTypes = []reflect.Type{
+		reflect.TypeOf((*json.Unmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*sql.Scanner)(nil)).Elem(),
+	}
+)
+
+// Schema returns a JSON Schema (draft 2020-12) of the documents the struct
+// type typ is decoded from. The nested objects and arrays the tag paths go
+// through are rebuilt, '#' and indexes standing for arrays, leaves are
+// typed after their fields, validation rules become the matching keywords
+// and the "required" option required properties. Nested struct types are
+// described under "$defs". Paths the schema can't follow, such as
+// modifiers, wildcards or JSONPath queries, leave that part of the
+// document unconstrained
+func Schema(typ reflect.Type) ([]byte, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't describe invalid type %v", typ)
+	}
+
+	s := &schemaGen{root: typ, names: map[reflect.Type]string{}, defs: map[string]*schemaNode{}}
+	root := s.structSchema(typ)
+	root.Schema = schemaDialect
+	root.Title = typ.Name()
+	if len(s.defs) > 0 {
+		root.Defs = s.defs
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaNode is a JSON Schema, its fields are written in this order
+type schemaNode struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	MinProperties        *int                   `json:"minProperties,omitempty"`
+	MaxProperties        *int                   `json:"maxProperties,omitempty"`
+	Properties           schemaProperties       `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *schemaNode            `json:"additionalProperties,omitempty"`
+	Items                *schemaNode            `json:"items,omitempty"`
+	AnyOf                []*schemaNode          `json:"anyOf,omitempty"`
+	Defs                 map[string]*schemaNode `json:"$defs,omitempty"`
+}
+
+// schemaProperties keeps properties in the order of the fields using them
+type schemaProperties []schemaProperty
+
+type schemaProperty struct {
+	name string
+	node *schemaNode
+}
+
+func (p schemaProperties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(prop.name)
+		node, err := json.Marshal(prop.node)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(node)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// property makes n describe an object and returns the schema of its
+// property name
+func (n *schemaNode) property(name string) *schemaNode {
+	if n.Type == nil {
+		n.Type = "object"
+	}
+
+	for _, prop := range n.Properties {
+		if prop.name == name {
+			return prop.node
+		}
+	}
+
+	prop := &schemaNode{}
+	n.Properties = append(n.Properties, schemaProperty{name: name, node: prop})
+	return prop
+}
+
+func (n *schemaNode) require(name string) {
+	for _, required := range n.Required {
+		if required == name {
+			return
+		}
+	}
+
+	n.Required = append(n.Required, name)
+}
+
+// item makes n describe an array and returns the schema of its items
+func (n *schemaNode) item() *schemaNode {
+	n.array()
+	if n.Items == nil {
+		n.Items = &schemaNode{}
+	}
+
+	return n.Items
+}
+
+func (n *schemaNode) array() {
+	if n.Type == nil {
+		n.Type = "array"
+	}
+}
+
+// merge adds the keywords of src that n doesn't have yet, so a field of a
+// struct type can share its object with fields reading into it, e.g.
+// `njson:"friends"` and `njson:"friends.#.first"`
+func (n *schemaNode) merge(src *schemaNode) {
+	if n.Items != nil && src.Items != nil {
+		n.Items.merge(src.Items)
+	}
+
+	dst := reflect.ValueOf(n).Elem()
+	from := reflect.ValueOf(src).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).IsZero() {
+			dst.Field(i).Set(from.Field(i))
+		}
+	}
+}
+
+// schemaGen builds the schema of one type
+type schemaGen struct {
+	root  reflect.Type
+	names map[reflect.Type]string // names of the struct types in defs
+	defs  map[string]*schemaNode
+}
+
+// structSchema describes the document a struct of type typ is decoded from
+func (s *schemaGen) structSchema(typ reflect.Type) *schemaNode {
+	node := &schemaNode{Type: "object"}
+
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+
+		path, opts, ok := UnmarshalOptions{}.fieldTag(sf)
+		if !ok || sf.PkgPath != "" || tagpath.IsJSONPath(path) {
+			continue
+		}
+
+		if isPointer(path, opts) {
+			var err error
+			if path, err = pointerPath(path); err != nil {
+				continue
+			}
+		}
+
+		s.addField(node, path, sf.Type, opts)
+	}
+
+	return node
+}
+
+// addField adds to node the path a field of type typ is read from
+func (s *schemaGen) addField(node *schemaNode, path string, typ reflect.Type, opts tagOptions) {
+	if path == "@this" {
+		node.merge(s.fieldSchema(typ, opts))
+		return
+	}
+
+	comps, seps := tagpath.Split(path)
+	for i, comp := range comps {
+		last := i == len(comps)-1
+
+		switch {
+		case seps[i] == '|':
+			// a pipe applies to the result as a whole, the rest is unknown
+			return
+		case comp == "#":
+			node.array()
+			if last {
+				// the number of elements, of any array
+				return
+			}
+			node = node.item()
+			typ = elemType(typ)
+		case strings.HasPrefix(comp, "#("):
+			node = node.item()
+			if strings.HasSuffix(comp, ")#") {
+				typ = elemType(typ)
+			}
+		case tagpath.IsIndex(comp):
+			node = node.item()
+		case tagpath.IsPlainKey(comp):
+			key := tagpath.UnescapeKey(comp)
+			if opts.has("required") {
+				node.property(key)
+				node.require(key)
+			}
+			node = node.property(key)
+		default:
+			return
+		}
+	}
+
+	node.merge(s.fieldSchema(typ, opts))
+}
+
+// elemType returns the type of the elements of a slice or array field, the
+// ones an array query fills
+func elemType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		return typ.Elem()
+	}
+
+	return typ
+}
+
+// fieldSchema describes the value a field of type typ is decoded from,
+// including the validation rules of its tag
+func (s *schemaGen) fieldSchema(typ reflect.Type, opts tagOptions) *schemaNode {
+	node := s.valueSchema(typ, opts)
+
+	v := typ
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	for _, rule := range rules {
+		if opts.has(rule) {
+			ruleSchema(node, v, rule, opts.get(rule))
+		}
+	}
+
+	return node
+}
+
+// valueSchema describes the value a field, slice element or map value of
+// type typ is decoded from
+func (s *schemaGen) valueSchema(typ reflect.Type, opts tagOptions) *schemaNode {
+	for _, opt := range opts {
+		switch {
+		case opt.name == "split":
+			return &schemaNode{Type: "string"}
+		case opt.name == "trim" || opt.name == "lower" || opt.name == "upper":
+		case transform(opt.name) != nil:
+			// a custom transform may accept anything
+			return &schemaNode{}
+		}
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		node := s.valueSchema(typ.Elem(), opts)
+		switch t := node.Type.(type) {
+		case string:
+			node.Type = []string{t, "null"}
+		case []string:
+			node.Type = append(t, "null")
+		default:
+			if node.Ref != "" {
+				node = &schemaNode{AnyOf: []*schemaNode{node, {Type: "null"}}}
+			}
+		}
+		return node
+	}
+
+	if typ == jsonNumberType || numberParser(typ) != nil {
+		return &schemaNode{Type: []string{"number", "string"}}
+	}
+
+	if values := registeredEnum(typ); values != nil {
+		node := &schemaNode{}
+		for _, name := range sortedKeys(values) {
+			node.Enum = append(node.Enum, name)
+		}
+		return node
+	}
+
+	if typ == timeType {
+		return &schemaNode{Type: "string", Format: "date-time"}
+	}
+
+	if reflect.PtrTo(typ).Implements(fieldDecoderType) {
+		if value, ok := typ.FieldByName("Value"); ok {
+			// Nullable
+			return s.valueSchema(reflect.PtrTo(value.Type), opts)
+		}
+	}
+
+	for _, u := range unmarshalerTypes {
+		if reflect.PtrTo(typ).Implements(u) {
+			return &schemaNode{}
+		}
+	}
+
+	scalar := typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array && typ.Kind() != reflect.Map && typ.Kind() != reflect.Struct
+
+	if opts.has("enum") && scalar {
+		node := &schemaNode{Type: "string"}
+		for _, name := range strings.Split(opts.get("enum"), "|") {
+			node.Enum = append(node.Enum, name)
+		}
+		return node
+	}
+
+	if opts.has("string") && scalar {
+		return &schemaNode{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &schemaNode{Type: "string"}
+	case reflect.Bool:
+		return &schemaNode{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &schemaNode{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &schemaNode{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &schemaNode{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &schemaNode{Type: "array", Items: s.valueSchema(typ.Elem(), opts)}
+	case reflect.Map:
+		return &schemaNode{Type: "object", AdditionalProperties: s.valueSchema(typ.Elem(), opts)}
+	case reflect.Struct:
+		return s.ref(typ)
+	default:
+		return &schemaNode{}
+	}
+}
+
+// ref returns a reference to the schema of the struct type typ, described
+// under "$defs" unless it is anonymous
+func (s *schemaGen) ref(typ reflect.Type) *schemaNode {
+	if typ == s.root {
+		return &schemaNode{Ref: "#"}
+	}
+
+	if typ.Name() == "" {
+		return s.structSchema(typ)
+	}
+
+	name, ok := s.names[typ]
+	if !ok {
+		name = typ.Name()
+		for i := 2; s.defs[name] != nil; i++ {
+			name = typ.Name() + strconv.Itoa(i)
+		}
+
+		// registered first so recursive types refer to it
+		s.names[typ] = name
+		s.defs[name] = &schemaNode{}
+		*s.defs[name] = *s.structSchema(typ)
+	}
+
+	return &schemaNode{Ref: "#/$defs/" + name}
+}
+
+// ruleSchema adds the keywords matching a validation rule to node, which
+// describes a value of type typ
+func ruleSchema(node *schemaNode, typ reflect.Type, rule, param string) {
+	n, _ := strconv.ParseFloat(param, 64)
+	count := int(n)
+
+	switch rule {
+	case "nonempty":
+		count = 1
+		fallthrough
+	case "len", "min":
+		switch typ.Kind() {
+		case reflect.String:
+			node.MinLength = &count
+		case reflect.Slice, reflect.Array:
+			node.MinItems = &count
+		case reflect.Map:
+			node.MinProperties = &count
+		default:
+			if rule == "min" {
+				node.Minimum = &n
+			}
+		}
+
+		if rule == "len" {
+			ruleSchema(node, typ, "max", param)
+		}
+	case "max":
+		switch typ.Kind() {
+		case reflect.String:
+			node.MaxLength = &count
+		case reflect.Slice, reflect.Array:
+			node.MaxItems = &count
+		case reflect.Map:
+			node.MaxProperties = &count
+		default:
+			node.Maximum = &n
+		}
+	case "oneof":
+		node.Enum = nil
+		for _, value := range strings.Split(param, "|") {
+			if typ.Kind() != reflect.String {
+				if _, err := strconv.ParseFloat(value, 64); err == nil {
+					node.Enum = append(node.Enum, json.Number(value))
+					continue
+				}
+			}
+			node.Enum = append(node.Enum, value)
+		}
+	case "email":
+		node.Format = "email"
+	case "regex":
+		node.Pattern = param
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,102 @@
+package njson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchema(t *testing.T) {
+	type Friend struct {
+		First string  `njson:"first,required"`
+		Age   int     `njson:"age,min=0,max=150"`
+		Best  *Friend `njson:"best"`
+	}
+
+	type User struct {
+		Name    string             `njson:"name.first,required,nonempty"`
+		Count   int                `njson:"friends.#"`
+		Names   []string           `njson:"friends.#.first"`
+		Friends []Friend           `njson:"friends"`
+		Status  string             `njson:"status,enum=active|banned"`
+		Tags    []string           `njson:"tags,split=,"`
+		Created *time.Time         `njson:"created"`
+		Item    string             `njson:"/items/0/name"`
+		Scores  map[string]float64 `njson:"scores,min=1"`
+		Nick    Nullable[string]   `njson:"nick"`
+		Level   uint               `njson:"level,oneof=1|2"`
+		Movie   string             `json:"fav.movie"`
+		Authors []string           `njson:"$..author"`
+		Reverse []string           `njson:"children|@reverse"`
+	}
+
+	actual, err := Schema(reflect.TypeOf(&User{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+	{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "User",
+		"type": "object",
+		"properties": {
+			"name": {
+				"type": "object",
+				"properties": {"first": {"type": "string", "minLength": 1}},
+				"required": ["first"]
+			},
+			"friends": {
+				"type": "array",
+				"items": {
+					"$ref": "#/$defs/Friend",
+					"type": "object",
+					"properties": {"first": {"type": "string"}}
+				}
+			},
+			"status": {"type": "string", "enum": ["active", "banned"]},
+			"tags": {"type": "string"},
+			"created": {"type": ["string", "null"], "format": "date-time"},
+			"items": {
+				"type": "array",
+				"items": {"type": "object", "properties": {"name": {"type": "string"}}}
+			},
+			"scores": {"type": "object", "minProperties": 1, "additionalProperties": {"type": "number"}},
+			"nick": {"type": ["string", "null"]},
+			"level": {"type": "integer", "minimum": 0, "enum": [1, 2]},
+			"fav.movie": {"type": "string"},
+			"children": {}
+		},
+		"required": ["name"],
+		"$defs": {
+			"Friend": {
+				"type": "object",
+				"properties": {
+					"first": {"type": "string"},
+					"age": {"type": "integer", "minimum": 0, "maximum": 150},
+					"best": {"anyOf": [{"$ref": "#/$defs/Friend"}, {"type": "null"}]}
+				},
+				"required": ["first"]
+			}
+		}
+	}`
+
+	var a, e interface{}
+	if err := json.Unmarshal(actual, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(e, a); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+
+	if _, err := Schema(reflect.TypeOf(0)); err == nil {
+		t.Error("error should not be nil for a non struct type")
+	}
+}
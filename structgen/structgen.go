@@ -0,0 +1,447 @@
+// Package structgen writes Go structs with njson tags that read a sample
+// JSON document, the library behind cmd/njson-struct
+package structgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/m7shapan/njson"
+	"github.com/m7shapan/njson/internal/tagpath"
+	"github.com/tidwall/gjson"
+)
+
+// Options configures Generate
+type Options struct {
+	// Package makes the output a complete Go file of that package, with
+	// its imports. Otherwise only the type declarations are written
+	Package string
+
+	// Paths lists the paths to map, a field each. When empty every value
+	// of the sample is mapped
+	Paths []string
+
+	// Depth is how many levels of keys a field's path spans when mapping
+	// every value, objects nested deeper become structs of their own.
+	// 0 flattens objects completely
+	Depth int
+}
+
+// Generate returns the Go declaration of a struct named name whose
+// fields use njson tags to read the values of the sample document, e.g.
+// `njson:"data.user.profile.name"`. Field types are inferred from the
+// values the way njson decodes them: RFC 3339 strings become time.Time,
+// integers too large for an int64 *big.Int, arrays of objects slices of
+// structs merging the keys of every element
+func Generate(name string, sample []byte, opts Options) ([]byte, error) {
+	if !gjson.ValidBytes(sample) {
+		return nil, fmt.Errorf("invalid json: %v", string(sample))
+	}
+
+	g := &structGen{opts: opts, types: map[string]bool{}, imports: map[string]bool{}}
+	doc := gjson.ParseBytes(sample)
+
+	if len(opts.Paths) == 0 {
+		if !doc.IsObject() {
+			return nil, fmt.Errorf("sample should be an object, got %s", doc.Type)
+		}
+		g.object(goName(name), doc)
+	} else if err := g.paths(goName(name), sample); err != nil {
+		return nil, err
+	}
+
+	var src bytes.Buffer
+	if opts.Package != "" {
+		fmt.Fprintf(&src, "package %s\n\n", opts.Package)
+
+		var imports []string
+		for path := range g.imports {
+			imports = append(imports, strconv.Quote(path))
+		}
+		sort.Strings(imports)
+
+		if len(imports) > 0 {
+			fmt.Fprintf(&src, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+		}
+	}
+	src.WriteString(strings.Join(g.decls, "\n"))
+
+	return format.Source(src.Bytes())
+}
+
+// structGen accumulates the declarations of Generate
+type structGen struct {
+	opts    Options
+	decls   []string
+	types   map[string]bool // type names declared so far
+	imports map[string]bool
+}
+
+// genField is a field of a generated struct
+type genField struct {
+	parts []string // keys the field name is made from, outermost first
+	path  string
+	value gjson.Result
+}
+
+// paths declares the struct name with a field for each of opts.Paths
+func (g *structGen) paths(name string, sample []byte) error {
+	var fields []genField
+	for _, tag := range g.opts.Paths {
+		result, err := njson.Lookup(sample, tag)
+		if err != nil {
+			return err
+		}
+
+		if !result.Exists() {
+			return fmt.Errorf("path %q not found in sample", tag)
+		}
+
+		fields = append(fields, genField{parts: pathKeys(tag), path: tag, value: result})
+	}
+
+	g.declare(name, fields)
+	return nil
+}
+
+// object declares a struct type for the object value and returns its name
+func (g *structGen) object(name string, value gjson.Result) string {
+	var fields []genField
+	g.flatten(value, nil, nil, func(keys, comps []string, value gjson.Result) {
+		fields = append(fields, genField{parts: keys, path: strings.Join(comps, "."), value: value})
+	})
+
+	return g.declare(name, fields)
+}
+
+// flatten calls field with the keys and escaped path components of each
+// value of obj that becomes a field, descending into nested objects as
+// deep as opts.Depth allows
+func (g *structGen) flatten(obj gjson.Result, keys, comps []string, field func(keys, comps []string, value gjson.Result)) {
+	obj.ForEach(func(key, value gjson.Result) bool {
+		k := append(keys[:len(keys):len(keys)], key.String())
+		c := append(comps[:len(comps):len(comps)], tagpath.EscapeKey(key.String()))
+
+		deeper := g.opts.Depth <= 0 || len(k) < g.opts.Depth
+		if value.IsObject() && len(value.Map()) > 0 && deeper {
+			g.flatten(value, k, c, field)
+		} else {
+			field(k, c, value)
+		}
+		return true
+	})
+}
+
+// declare writes the declaration of a struct with fields and returns its
+// name. The structs its fields need are named after them and declared next
+func (g *structGen) declare(name string, fields []genField) string {
+	typeName := g.typeName(name)
+
+	slot := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	names := fieldNames(fields)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	for i, f := range fields {
+		tag := "njson:" + strconv.Quote(f.path)
+		if strings.Contains(tag, "`") {
+			tag = strconv.Quote(tag)
+		} else {
+			tag = "`" + tag + "`"
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", names[i], g.typeOf(typeName+names[i], f.value), tag)
+	}
+	b.WriteString("}\n")
+
+	g.decls[slot] = b.String()
+	return typeName
+}
+
+// typeOf returns the Go type of value, declaring the struct types it needs
+// under names starting with name
+func (g *structGen) typeOf(name string, value gjson.Result) string {
+	if value.IsArray() {
+		return "[]" + g.elemType(name, value.Array())
+	}
+
+	if value.IsObject() {
+		if len(value.Map()) == 0 {
+			return "map[string]interface{}"
+		}
+		return g.object(name, value)
+	}
+
+	typ := scalarType(value)
+	switch typ {
+	case "time.Time":
+		g.imports["time"] = true
+	case "*big.Int":
+		g.imports["math/big"] = true
+	}
+
+	return typ
+}
+
+// scalarType returns the Go type of a value other than an object or array
+func scalarType(value gjson.Result) string {
+	switch value.Type {
+	case gjson.String:
+		if _, err := time.Parse(time.RFC3339, value.String()); err == nil {
+			return "time.Time"
+		}
+		return "string"
+	case gjson.Number:
+		if strings.ContainsAny(value.Raw, ".eE") {
+			return "float64"
+		}
+		if _, err := strconv.ParseInt(value.Raw, 10, 64); err != nil {
+			return "*big.Int"
+		}
+		if value.Int() > math.MaxInt32 || value.Int() < math.MinInt32 {
+			return "int64"
+		}
+		return "int"
+	case gjson.True, gjson.False:
+		return "bool"
+	default:
+		return "interface{}"
+	}
+}
+
+// numberRank orders number types by the values they hold, so that mixed
+// numbers get the widest type
+var numberRank = map[string]int{"int": 1, "int64": 2, "*big.Int": 3, "float64": 4}
+
+// elemType returns the Go type of the elements of an array: objects are
+// merged into one struct, numbers get the widest of their types and other
+// mixed values interface{}
+func (g *structGen) elemType(name string, elems []gjson.Result) string {
+	var objects, arrays, scalars []gjson.Result
+	for _, elem := range elems {
+		switch {
+		case elem.Type == gjson.Null:
+		case elem.IsObject():
+			objects = append(objects, elem)
+		case elem.IsArray():
+			arrays = append(arrays, elem)
+		default:
+			scalars = append(scalars, elem)
+		}
+	}
+
+	switch {
+	case len(objects) > 0 && len(arrays)+len(scalars) == 0:
+		return g.typeOf(name, mergeObjects(objects))
+	case len(arrays) > 0 && len(objects)+len(scalars) == 0:
+		var all []gjson.Result
+		for _, elem := range arrays {
+			all = append(all, elem.Array()...)
+		}
+		return "[]" + g.elemType(name, all)
+	case len(scalars) == 0 || len(objects)+len(arrays) > 0:
+		return "interface{}"
+	}
+
+	widest := scalars[0]
+	for _, elem := range scalars[1:] {
+		typ, widestType := scalarType(elem), scalarType(widest)
+		switch {
+		case typ == widestType:
+		case numberRank[typ] > 0 && numberRank[widestType] > 0:
+			if numberRank[typ] > numberRank[widestType] {
+				widest = elem
+			}
+		default:
+			return "interface{}"
+		}
+	}
+
+	return g.typeOf(name, widest)
+}
+
+// mergeObjects returns an object with the keys of all objects, each with
+// its first non-null value
+func mergeObjects(objects []gjson.Result) gjson.Result {
+	var keys []string
+	values := map[string]string{}
+	for _, obj := range objects {
+		obj.ForEach(func(key, value gjson.Result) bool {
+			k := key.String()
+			if _, ok := values[k]; !ok {
+				keys = append(keys, k)
+			}
+			if old, ok := values[k]; !ok || old == "null" {
+				values[k] = value.Raw
+			}
+			return true
+		})
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		quoted, _ := json.Marshal(k)
+		b.Write(quoted)
+		b.WriteByte(':')
+		b.WriteString(values[k])
+	}
+	b.WriteByte('}')
+
+	return gjson.Parse(b.String())
+}
+
+// typeName returns name, or name with a number if it is already declared
+func (g *structGen) typeName(name string) string {
+	typeName := name
+	for i := 2; g.types[typeName]; i++ {
+		typeName = name + strconv.Itoa(i)
+	}
+	g.types[typeName] = true
+
+	return typeName
+}
+
+// fieldNames names each field after its innermost key, adding outer keys
+// for the fields that would otherwise share a name
+func fieldNames(fields []genField) []string {
+	names := make([]string, len(fields))
+	used := map[string]int{}
+	for n := 1; ; n++ {
+		done := true
+		for i, f := range fields {
+			if n > 1 && used[names[i]] < 2 {
+				continue
+			}
+
+			parts := f.parts
+			if len(parts) > n {
+				parts = parts[len(parts)-n:]
+			}
+			names[i] = goName(strings.Join(parts, "_"))
+			done = done && len(parts) == len(f.parts)
+		}
+
+		used = map[string]int{}
+		for _, name := range names {
+			used[name]++
+		}
+
+		clash := false
+		for _, count := range used {
+			clash = clash || count > 1
+		}
+
+		if !clash || done {
+			break
+		}
+	}
+
+	// names that still clash are numbered
+	seen := map[string]int{}
+	for i, name := range names {
+		seen[name]++
+		if used[name] > 1 && seen[name] > 1 {
+			names[i] = name + strconv.Itoa(seen[name])
+		}
+	}
+
+	return names
+}
+
+var (
+	keyPattern    = regexp.MustCompile(`[\p{L}\p{N}_-]+`)
+	filterPattern = regexp.MustCompile(`\[\?[^\]]*\]`)
+)
+
+// pathKeys returns the object keys named in path, ignoring indexes, array
+// queries and modifiers
+func pathKeys(path string) (keys []string) {
+	if tagpath.IsPointer(path) || tagpath.IsJSONPath(path) {
+		// drop filters, only their field names would show up
+		path = filterPattern.ReplaceAllString(path, "")
+		for _, key := range keyPattern.FindAllString(path, -1) {
+			if !tagpath.IsIndex(key) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	comps, _ := tagpath.Split(path)
+	for _, comp := range comps {
+		if tagpath.IsPlainKey(comp) && !tagpath.IsIndex(comp) {
+			keys = append(keys, tagpath.UnescapeKey(comp))
+		}
+	}
+
+	return keys
+}
+
+// commonInitialisms are written in upper case in Go names
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName turns a JSON key into an exported Go identifier, e.g.
+// "user_id" into "UserID" and "fav.movie" into "FavMovie"
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		// split camelCase words so their parts are capitalized alike
+		for _, part := range splitCamel(word) {
+			if upper := strings.ToUpper(part); commonInitialisms[upper] {
+				b.WriteString(upper)
+				continue
+			}
+
+			r := []rune(part)
+			b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+		}
+	}
+
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		return "F" + name
+	}
+
+	return name
+}
+
+// splitCamel splits a camelCase word before each upper case letter that
+// follows a lower case one
+func splitCamel(word string) (parts []string) {
+	r := []rune(word)
+	start := 0
+	for i := 1; i < len(r); i++ {
+		if unicode.IsUpper(r[i]) && unicode.IsLower(r[i-1]) {
+			parts = append(parts, string(r[start:i]))
+			start = i
+		}
+	}
+
+	return append(parts, string(r[start:]))
+}
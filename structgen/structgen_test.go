@@ -0,0 +1,169 @@
+package structgen
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/m7shapan/njson"
+)
+
+const sampleJSON = `
+{
+	"data": {
+		"user": {
+			"id": 7,
+			"user_name": "tom",
+			"profile": {"name": "Tom", "created_at": "2021-06-01T10:00:00Z"}
+		},
+		"tags": ["a", "b"],
+		"friends": [{"name": "Dale", "age": 44}, {"name": "Jane", "score": 1.5, "age": null}],
+		"fav.movie": "Deer Hunter",
+		"balance": 123456789012345678901,
+		"nums": [1, 2.5],
+		"matrix": [[1, 2], [3]]
+	},
+	"name": "root"
+}`
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate("user", []byte(sampleJSON), Options{Package: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "package api\n\n" +
+		"import (\n\t\"math/big\"\n\t\"time\"\n)\n\n" +
+		"type User struct {\n" +
+		"\tID          int           `njson:\"data.user.id\"`\n" +
+		"\tUserName    string        `njson:\"data.user.user_name\"`\n" +
+		"\tProfileName string        `njson:\"data.user.profile.name\"`\n" +
+		"\tCreatedAt   time.Time     `njson:\"data.user.profile.created_at\"`\n" +
+		"\tTags        []string      `njson:\"data.tags\"`\n" +
+		"\tFriends     []UserFriends `njson:\"data.friends\"`\n" +
+		"\tFavMovie    string        `njson:\"data.fav\\\\.movie\"`\n" +
+		"\tBalance     *big.Int      `njson:\"data.balance\"`\n" +
+		"\tNums        []float64     `njson:\"data.nums\"`\n" +
+		"\tMatrix      [][]int       `njson:\"data.matrix\"`\n" +
+		"\tName        string        `njson:\"name\"`\n" +
+		"}\n\n" +
+		"type UserFriends struct {\n" +
+		"\tName  string  `njson:\"name\"`\n" +
+		"\tAge   int     `njson:\"age\"`\n" +
+		"\tScore float64 `njson:\"score\"`\n" +
+		"}\n"
+
+	if diff := cmp.Diff(expected, string(src)); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+
+	// the same declarations, compiled, decode the sample
+	type UserFriends struct {
+		Name  string  `njson:"name"`
+		Age   int     `njson:"age"`
+		Score float64 `njson:"score"`
+	}
+
+	type User struct {
+		ID          int           `njson:"data.user.id"`
+		UserName    string        `njson:"data.user.user_name"`
+		ProfileName string        `njson:"data.user.profile.name"`
+		CreatedAt   time.Time     `njson:"data.user.profile.created_at"`
+		Tags        []string      `njson:"data.tags"`
+		Friends     []UserFriends `njson:"data.friends"`
+		FavMovie    string        `njson:"data.fav\\.movie"`
+		Balance     *big.Int      `njson:"data.balance"`
+		Nums        []float64     `njson:"data.nums"`
+		Matrix      [][]int       `njson:"data.matrix"`
+		Name        string        `njson:"name"`
+	}
+
+	actual := User{}
+	if err := njson.Unmarshal([]byte(sampleJSON), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	balance, _ := new(big.Int).SetString("123456789012345678901", 10)
+	expectedUser := User{
+		ID:          7,
+		UserName:    "tom",
+		ProfileName: "Tom",
+		CreatedAt:   time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		Tags:        []string{"a", "b"},
+		Friends:     []UserFriends{{Name: "Dale", Age: 44}, {Name: "Jane", Score: 1.5}},
+		FavMovie:    "Deer Hunter",
+		Balance:     balance,
+		Nums:        []float64{1, 2.5},
+		Matrix:      [][]int{{1, 2}, {3}},
+		Name:        "root",
+	}
+
+	if diff := cmp.Diff(expectedUser, actual, cmp.Comparer(func(a, b *big.Int) bool { return a.Cmp(b) == 0 })); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+}
+
+func TestGenerateDepth(t *testing.T) {
+	src, err := Generate("User", []byte(`{"data": {"user": {"id": 7, "profile": {"name": "Tom"}}}}`), Options{Depth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "type User struct {\n" +
+		"\tUser UserUser `njson:\"data.user\"`\n" +
+		"}\n\n" +
+		"type UserUser struct {\n" +
+		"\tID   int    `njson:\"id\"`\n" +
+		"\tName string `njson:\"profile.name\"`\n" +
+		"}\n"
+
+	if diff := cmp.Diff(expected, string(src)); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+}
+
+func TestGeneratePaths(t *testing.T) {
+	paths := []string{"data.user.profile.name", "name", "data.friends.#.name", "/data/user/id", "$..age"}
+
+	src, err := Generate("User", []byte(sampleJSON), Options{Paths: paths})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "type User struct {\n" +
+		"\tProfileName string   `njson:\"data.user.profile.name\"`\n" +
+		"\tName        string   `njson:\"name\"`\n" +
+		"\tFriendsName []string `njson:\"data.friends.#.name\"`\n" +
+		"\tID          int      `njson:\"/data/user/id\"`\n" +
+		"\tAge         []int    `njson:\"$..age\"`\n" +
+		"}\n"
+
+	if diff := cmp.Diff(expected, string(src)); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+
+	if _, err := Generate("User", []byte(sampleJSON), Options{Paths: []string{"data.nmae"}}); err == nil {
+		t.Error("error should not be nil for a path missing from the sample")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"name":       "Name",
+		"user_id":    "UserID",
+		"fav.movie":  "FavMovie",
+		"createdAt":  "CreatedAt",
+		"api-url":    "APIURL",
+		"2fa":        "F2fa",
+		"élan":       "Élan",
+		"__":         "Field",
+		"HTTPStatus": "HTTPStatus",
+	}
+
+	for key, expected := range tests {
+		if actual := goName(key); actual != expected {
+			t.Errorf("%q: expected %s, got %s", key, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package njson
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/m7shapan/njson/internal/tagpath"
+)
+
+// tagOption is a single option following the path in a njson tag
+type tagOption struct {
+	name  string
+	value string
+}
+
+// tagOptions holds the comma separated options that follow the path in a
+// njson tag, e.g. `njson:"friends,elements=skip"`, in the order they are given
+type tagOptions []tagOption
+
+// has reports whether the option name was given
+func (o tagOptions) has(name string) bool {
+	for _, opt := range o {
+		if opt.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// get returns the value of the option name, or "" if it has none
+func (o tagOptions) get(name string) string {
+	for _, opt := range o {
+		if opt.name == name {
+			return opt.value
+		}
+	}
+
+	return ""
+}
+
+// parseTag splits a njson tag into its path and options. Commas nested in
+// brackets, braces, parentheses or quotes belong to the path, so queries
+// such as `friends.#(age>20)#.{first,last}` keep working, and a comma can be
+// escaped with '\' like any other path character. A comma right after '=' is
+// the option's value, which allows `split=,`
+func parseTag(tag string) (path string, opts tagOptions) {
+	parts := splitTag(tag)
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+
+		name, value := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			name, value = part[:i], part[i+1:]
+		}
+		opts = append(opts, tagOption{name: strings.TrimSpace(name), value: value})
+	}
+
+	return parts[0], opts
+}
+
+func splitTag(tag string) (parts []string) {
+	depth := 0
+	quoted := false
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '=' && len(parts) > 0 && depth == 0 && i+1 < len(tag) && tag[i+1] == ',':
+			i++
+		case c == '[' || c == '{' || c == '(':
+			depth++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, tag[start:])
+}
+
+// fieldTag returns the path and options a struct field is decoded with. ok
+// is false if the field has none of the configured tags and should be
+// skipped. Only njson tags carry path semantics, the key of a "json" tag is
+// escaped so it is looked up literally, as encoding/json would
+func (o UnmarshalOptions) fieldTag(sf reflect.StructField) (path string, opts tagOptions, ok bool) {
+	if o.JSONTag == JSONTagPreferred && validTag(sf, jsonTag) {
+		path, opts = parseJSONTag(sf)
+		return tagpath.EscapeKey(path), opts, true
+	}
+
+	tagName := o.TagName
+	if tagName == "" {
+		tagName = njsonTag
+	}
+
+	for _, name := range append([]string{tagName}, o.FallbackTags...) {
+		if validTag(sf, name) {
+			path, opts = parseTag(sf.Tag.Get(name))
+			return path, opts, true
+		}
+	}
+
+	if validTag(sf, njsonPathTag) {
+		path, opts = parseTag(sf.Tag.Get(njsonPathTag))
+		switch {
+		case tagpath.IsJSONPath(path):
+		case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "["):
+			path = "$" + path
+		default:
+			path = "$." + path
+		}
+		return path, opts, true
+	}
+
+	if o.JSONTag == JSONTagFallback && validTag(sf, jsonTag) {
+		path, opts = parseJSONTag(sf)
+		return tagpath.EscapeKey(path), opts, true
+	}
+
+	return "", nil, false
+}
+
+// parseJSONTag reads a "json" tag the way encoding/json does: the key comes
+// before the first comma and defaults to the field name, "-," is the key "-",
+// and of its options only "string" affects decoding
+func parseJSONTag(sf reflect.StructField) (key string, opts tagOptions) {
+	tag := sf.Tag.Get(jsonTag)
+
+	key, rest := tag, ""
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		key, rest = tag[:i], tag[i+1:]
+	}
+
+	if key == "" {
+		key = sf.Name
+	}
+
+	for _, opt := range strings.Split(rest, ",") {
+		if opt == "string" {
+			opts = append(opts, tagOption{name: opt})
+		}
+	}
+
+	return key, opts
+}
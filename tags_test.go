@@ -0,0 +1,102 @@
+package njson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		path string
+		opts tagOptions
+	}{
+		{tag: "name.first", path: "name.first"},
+		{tag: "friends,elements=skip", path: "friends", opts: tagOptions{{name: "elements", value: "skip"}}},
+		{tag: "friends.#(age>20)#.{first,last},elements=zero", path: "friends.#(age>20)#.{first,last}", opts: tagOptions{{name: "elements", value: "zero"}}},
+		{tag: `friends.#(name=="a,b").age`, path: `friends.#(name=="a,b").age`},
+		{tag: `a\,b,elements=fail`, path: `a\,b`, opts: tagOptions{{name: "elements", value: "fail"}}},
+		{tag: "tags,split=,,trim", path: "tags", opts: tagOptions{{name: "split", value: ","}, {name: "trim"}}},
+	}
+
+	for _, tt := range tests {
+		path, opts := parseTag(tt.tag)
+		if path != tt.path {
+			t.Errorf("%s: path should be %q, got %q", tt.tag, tt.path, path)
+		}
+
+		if diff := cmp.Diff(tt.opts, opts, cmp.AllowUnexported(tagOption{})); diff != "" {
+			t.Errorf("%s: %s", tt.tag, diff)
+		}
+	}
+}
+
+func TestUnmarshalTagName(t *testing.T) {
+	json := `{"name": {"first": "Mohamed", "last": "Shapan"}, "first": "top", "age": 26, "email": "m@example.com"}`
+
+	type User struct {
+		First string `api:"name.first" json:"first"`
+		Last  string `legacy:"name.last"`
+		Age   int    `njson:"age" json:"-"`
+		Email string `json:"email"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     UnmarshalOptions
+		expected User
+	}{
+		{
+			name:     "default",
+			expected: User{First: "top", Age: 26, Email: "m@example.com"},
+		},
+		{
+			name:     "custom tag",
+			opts:     UnmarshalOptions{TagName: "api", FallbackTags: []string{"legacy", "njson"}, JSONTag: JSONTagFallback},
+			expected: User{First: "Mohamed", Last: "Shapan", Age: 26, Email: "m@example.com"},
+		},
+		{
+			name:     "json ignored",
+			opts:     UnmarshalOptions{TagName: "api", JSONTag: JSONTagIgnored},
+			expected: User{First: "Mohamed"},
+		},
+	}
+
+	for _, tt := range tests {
+		actual := User{}
+
+		if err := tt.opts.Unmarshal([]byte(json), &actual); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+
+		if diff := cmp.Diff(tt.expected, actual); diff != "" {
+			t.Errorf("%s: %s", tt.name, diff)
+		}
+	}
+}
+
+func TestUnmarshalJSONTagOptions(t *testing.T) {
+	json := `{"name": "Mohamed", "Age": 26, "-": "dash", "count": "7", "skip": "no"}`
+
+	type User struct {
+		Name  string `json:"name,omitempty"`
+		Age   int    `json:",omitempty"`
+		Dash  string `json:"-,"`
+		Count int    `json:"count,string"`
+		Skip  string `json:"-"`
+	}
+
+	actual := User{}
+
+	err := UnmarshalOptions{Strict: true}.Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := User{Name: "Mohamed", Age: 26, Dash: "dash", Count: 7}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
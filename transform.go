@@ -0,0 +1,134 @@
+package njson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// TransformFunc rewrites the value found at a field's path before it is
+// assigned. param is the option's value, e.g. "," for `split=,`
+type TransformFunc func(value gjson.Result, param string) (gjson.Result, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]TransformFunc{}
+)
+
+// reservedOptions are tag options with a meaning of their own, they can't be
+// used as transform names
+var reservedOptions = []string{"string", "enum", "elements", "pointer"}
+
+func init() {
+	RegisterTransform("trim", stringTransform(strings.TrimSpace))
+	RegisterTransform("lower", stringTransform(strings.ToLower))
+	RegisterTransform("upper", stringTransform(strings.ToUpper))
+	RegisterTransform("split", split)
+}
+
+// RegisterTransform makes name usable as a tag option that transforms the
+// value at the field's path, such as `njson:"email,trim,lower"`. Transforms
+// run in the order they appear in the tag, between the lookup of the path
+// and the assignment of the field. trim, lower, upper and split are
+// registered by default
+func RegisterTransform(name string, fn TransformFunc) {
+	for _, reserved := range append(reservedOptions, rules...) {
+		if name == reserved {
+			panic(fmt.Errorf("transform name %q is reserved", name))
+		}
+	}
+
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+
+	transforms[name] = fn
+}
+
+func transform(name string) TransformFunc {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	return transforms[name]
+}
+
+// transformField applies the transforms in the options of f to result
+func transformField(result gjson.Result, f fieldInfo) (gjson.Result, error) {
+	if !result.Exists() {
+		return result, nil
+	}
+
+	for _, opt := range f.opts {
+		fn := transform(opt.name)
+		if fn == nil {
+			continue
+		}
+
+		var err error
+		result, err = fn(result, opt.value)
+		if err != nil {
+			return result, fmt.Errorf("transform %s: %w", opt.name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// stringTransform turns fn into a transform of strings. Arrays have fn
+// applied to each of their string elements, other values are left alone
+func stringTransform(fn func(string) string) TransformFunc {
+	return func(value gjson.Result, _ string) (gjson.Result, error) {
+		switch {
+		case value.Type == gjson.String:
+			return stringResult(fn(value.String())), nil
+		case value.IsArray():
+			var elems []gjson.Result
+			for _, elem := range value.Array() {
+				if elem.Type == gjson.String {
+					elem = stringResult(fn(elem.String()))
+				}
+				elems = append(elems, elem)
+			}
+			return arrayResult(elems), nil
+		default:
+			return value, nil
+		}
+	}
+}
+
+// split turns a string into an array of strings separated by sep, "," if
+// none is given
+func split(value gjson.Result, sep string) (gjson.Result, error) {
+	if value.Type != gjson.String {
+		return value, nil
+	}
+
+	if sep == "" {
+		sep = ","
+	}
+
+	var elems []gjson.Result
+	if value.String() != "" {
+		for _, s := range strings.Split(value.String(), sep) {
+			elems = append(elems, stringResult(s))
+		}
+	}
+
+	return arrayResult(elems), nil
+}
+
+func stringResult(s string) gjson.Result {
+	raw, _ := json.Marshal(s)
+	return gjson.ParseBytes(raw)
+}
+
+func arrayResult(elems []gjson.Result) gjson.Result {
+	raws := make([]string, len(elems))
+	for i, elem := range elems {
+		raws[i] = elem.Raw
+	}
+
+	return gjson.Parse("[" + strings.Join(raws, ",") + "]")
+}
@@ -0,0 +1,67 @@
+package njson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tidwall/gjson"
+)
+
+func TestUnmarshalTransform(t *testing.T) {
+	RegisterTransform("prefix", func(value gjson.Result, param string) (gjson.Result, error) {
+		if value.Type != gjson.String {
+			return value, errors.New("not a string")
+		}
+		return stringResult(param + value.String()), nil
+	})
+
+	json := `
+	{
+		"email": "  Mohamed@Example.COM ",
+		"code": "ab",
+		"tags": "go, json ,njson",
+		"paths": "a/b/c",
+		"names": [" asma ", "AHMED"],
+		"id": "42"
+	}`
+
+	type User struct {
+		Email string   `njson:"email,trim,lower"`
+		Code  string   `njson:"code,upper,len=2"`
+		Tags  []string `njson:"tags,split=,,trim"`
+		Paths []string `njson:"paths,split=/"`
+		Names []string `njson:"names,trim,lower"`
+		ID    string   `njson:"id,prefix=user-"`
+	}
+
+	actual := User{}
+
+	err := Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := User{
+		Email: "mohamed@example.com",
+		Code:  "AB",
+		Tags:  []string{"go", "json", "njson"},
+		Paths: []string{"a", "b", "c"},
+		Names: []string{"asma", "ahmed"},
+		ID:    "user-42",
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	type Invalid struct {
+		ID string `njson:"names,prefix=user-"`
+	}
+
+	err = Unmarshal([]byte(json), &Invalid{})
+	if err == nil || !strings.Contains(err.Error(), "transform prefix") {
+		t.Errorf("error should come from the transform, got %v", err)
+	}
+}
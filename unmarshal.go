@@ -1,139 +1,554 @@
 package njson
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
+	"strconv"
+	"time"
 
+	"github.com/m7shapan/njson/internal/tagpath"
 	"github.com/tidwall/gjson"
 )
 
 const (
-	njsonTag = "njson"
-	jsonTag  = "json"
+	njsonTag     = "njson"
+	njsonPathTag = "njsonpath"
+	jsonTag      = "json"
 )
 
-var jsonNumberType = reflect.TypeOf(json.Number(""))
+var (
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	timeType       = reflect.TypeOf(time.Time{})
+)
 
 // Unmarshal used to unmarshal nested json using "njson" tag
-func Unmarshal(data []byte, v interface{}) (err error) {
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalOptions{}.Unmarshal(data, v)
+}
+
+// Unmarshal used to unmarshal nested json using "njson" tag and the
+// behaviour configured by o
+func (o UnmarshalOptions) Unmarshal(data []byte, v interface{}) (err error) {
 	if !gjson.ValidBytes(data) {
 		return fmt.Errorf("invalid json: %v", string(data))
 	}
 
-	// catch code panic and return error message
-	defer func() {
-		if r := recover(); r != nil {
-			switch x := r.(type) {
-			case string:
-				err = errors.New(x)
-			case error:
-				err = x
-			default:
-				err = fmt.Errorf("unknown panic: %v", r)
-			}
-		}
-	}()
-
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return fmt.Errorf("can't unmarshal to invalid type %v", reflect.TypeOf(v))
 	}
-	elem := rv.Elem()
-	typeOfT := elem.Type()
+
+	if err := o.checkSchema(data, rv.Elem().Type()); err != nil {
+		return err
+	}
+
+	if u, ok := v.(Unmarshaler); ok && o.generated() {
+		return u.UnmarshalNJSON(data)
+	}
+
+	// catch code panic and return error message
+	defer catchPanic(&err)
+
+	d := &decodeState{opts: o}
+	d.decodeStruct(data, rv.Elem(), fieldInfo{})
+
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+
+	return
+}
+
+// decodeState holds the options and the collected errors of one decode call
+type decodeState struct {
+	opts UnmarshalOptions
+	errs Errors
+}
+
+// fieldInfo describes the value being decoded. Its Go path, e.g.
+// "Friends[1].Age", and njson path, e.g. "friends.1.age", are only built
+// when needed, from the chain of values holding it
+type fieldInfo struct {
+	parent *fieldInfo
+	kind   fieldKind
+	field  string // struct field name
+	tag    string // tag path of a struct field, or key of a map value
+	index  int    // index of a slice element
+	opts   tagOptions
+}
+
+type fieldKind int
+
+const (
+	structField fieldKind = iota
+	sliceElem
+	mapValue
+	// queryElem is an element picked by an array query, it has no path of
+	// its own
+	queryElem
+)
+
+// child returns the fieldInfo of a struct field read from path
+func (f *fieldInfo) child(name, path string, opts tagOptions) fieldInfo {
+	return fieldInfo{parent: f, field: name, tag: path, opts: opts}
+}
+
+// elem returns the fieldInfo of the i-th element of a slice, elements
+// share the options of the field holding them
+func (f *fieldInfo) elem(i int) fieldInfo {
+	return fieldInfo{parent: f, kind: sliceElem, index: i, opts: f.opts}
+}
+
+// key returns the fieldInfo of the value stored under key in a map, values
+// share the options of the field holding them
+func (f *fieldInfo) key(key string) fieldInfo {
+	return fieldInfo{parent: f, kind: mapValue, tag: key, opts: f.opts}
+}
+
+// name returns the Go path of f
+func (f *fieldInfo) name() string {
+	var parent string
+	if f.parent != nil {
+		parent = f.parent.name()
+	}
+
+	switch f.kind {
+	case sliceElem, queryElem:
+		return parent + "[" + strconv.Itoa(f.index) + "]"
+	case mapValue:
+		return parent + "[" + strconv.Quote(f.tag) + "]"
+	default:
+		return joinField(parent, f.field)
+	}
+}
+
+// path returns the njson path of f
+func (f *fieldInfo) path() string {
+	var parent string
+	if f.parent != nil {
+		parent = f.parent.path()
+	}
+
+	switch f.kind {
+	case sliceElem:
+		return joinPath(parent, strconv.Itoa(f.index))
+	case queryElem:
+		return parent
+	default:
+		return joinPath(parent, f.tag)
+	}
+}
+
+func (d *decodeState) decodeStruct(data []byte, elem reflect.Value, parent fieldInfo) {
+	if !d.beforeHook(data, elem, parent) {
+		return
+	}
+
 	for i := 0; i < elem.NumField(); i++ {
-		field := elem.Field(i)
+		d.decodeStructField(data, elem, i, &parent)
+	}
 
-		// Check that the tag is either "json" or "njson", and can be set
-		if (!validTag(typeOfT.Field(i), njsonTag) && !validTag(typeOfT.Field(i), jsonTag)) || !field.CanSet() {
-			continue
+	d.afterHooks(elem, parent)
+}
+
+// decodeStructField decodes the i-th field of the struct elem from data
+func (d *decodeState) decodeStructField(data []byte, elem reflect.Value, i int, parent *fieldInfo) {
+	field := elem.Field(i)
+	sf := elem.Type().Field(i)
+
+	// Check that the field is tagged and can be set
+	tag, opts, ok := d.opts.fieldTag(sf)
+	if !ok || !field.CanSet() {
+		return
+	}
+
+	if isPointer(tag, opts) {
+		path, err := pointerPath(tag)
+		if err != nil {
+			d.fail(parent.child(sf.Name, tag, opts), err)
+			return
 		}
+		tag = path
+	}
 
-		// Assume "njson" by default, but change to "json" if tag matches
-		fieldName := typeOfT.Field(i).Tag.Get(njsonTag)
-		if validTag(typeOfT.Field(i), jsonTag) {
-			fieldName = typeOfT.Field(i).Tag.Get(jsonTag)
+	f := parent.child(sf.Name, tag, opts)
 
-			// Only support true "json" tags:
-			// if a tag is nested, it must use the "njson" tag
-			if len(strings.Split(fieldName, ".")) > 1 {
-				return fmt.Errorf("invalid json tag: %s", fieldName)
-			}
+	// get field value by tag
+	result, err := d.lookup(data, tag)
+	if err != nil {
+		d.fail(f, err)
+		return
+	}
+
+	if d.opts.PresentFields != nil {
+		d.opts.PresentFields[f.name()] = result.Exists()
+	}
+	if d.opts.PresentPaths != nil {
+		d.opts.PresentPaths[f.path()] = result.Exists()
+	}
+
+	result, err = transformField(result, f)
+	if err != nil {
+		d.fail(f, err)
+		return
+	}
+
+	// a field that failed to decode already has its error
+	if d.decodeField(result, field, f) {
+		d.validateField(result, field, f)
+	}
+}
+
+// lookup returns the value at path in data, path being either a gjson path
+// or a JSONPath query
+func (d *decodeState) lookup(data []byte, path string) (gjson.Result, error) {
+	if tagpath.IsJSONPath(path) {
+		return getJSONPath(data, path)
+	}
+
+	if d.opts.CaseInsensitive || d.opts.FuzzyKeys {
+		return getKeys(data, path, d.keyMatchers()...), nil
+	}
+
+	return gjson.GetBytes(data, path), nil
+}
+
+// keyMatchers returns how keys without an exact match are looked up,
+// case-insensitive matches win over fuzzy ones
+func (d *decodeState) keyMatchers() []func(key, want string) bool {
+	if d.opts.FuzzyKeys {
+		return []func(key, want string) bool{foldKey, fuzzyKey}
+	}
+
+	return []func(key, want string) bool{foldKey}
+}
+
+// decodeField sets field from result. A failure either aborts the decode or,
+// when collecting errors, is recorded and leaves the field as far as it got.
+// It reports whether the field was set without failure
+func (d *decodeState) decodeField(result gjson.Result, field reflect.Value, f fieldInfo) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.fail(f, panicError(r))
 		}
+	}()
+
+	d.setField(result, field, f)
+
+	return true
+}
+
+// fail reports err for the field f, aborting the decode unless errors are
+// being collected
+func (d *decodeState) fail(f fieldInfo, err error) {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		fe = &FieldError{Field: f.name(), Path: f.path(), Err: err}
+	}
+
+	if !d.opts.CollectErrors {
+		panic(fe)
+	}
 
-		// get field value by tag
-		result := gjson.GetBytes(data, fieldName)
+	d.errs = append(d.errs, fe)
+}
+
+// setField converts result to the type of field and assigns it
+func (d *decodeState) setField(result gjson.Result, field reflect.Value, f fieldInfo) {
+	if d.opts.Merge && !result.Exists() {
+		return
+	}
+
+	// if field type json.Number
+	if field.Kind() == reflect.String && field.Type() == jsonNumberType {
+		field.SetString(result.String())
+		return
+	}
+
+	if d.decodeNumber(result, field, f) {
+		return
+	}
+
+	if d.decodeEnum(result, field, f) {
+		return
+	}
+
+	if d.decodeCustom(result, field, f) {
+		return
+	}
+
+	// null clears the field: pointers, maps, slices and interfaces become nil
+	if result.Type == gjson.Null && result.Exists() {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+
+	if d.opts.Merge && d.mergeField(result, field, f) {
+		return
+	}
+
+	// a missing path leaves the zero value, apart from slices which are
+	// always made so they decode to an empty slice
+	if !result.Exists() && field.Kind() != reflect.Slice {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+
+	// structs behind pointers are decoded field by field, as other structs
+	if field.Kind() == reflect.Ptr && decodesFields(field.Type().Elem()) {
+		d.decodePointer(result, field, f)
+		return
+	}
+
+	if f.opts.has("string") && !isStructureType(field.Kind().String()) {
+		parseQuoted(result, field)
+		return
+	}
+
+	if d.opts.Strict {
+		checkStrict(result, field.Type())
+	}
 
-		// if field type json.Number
-		if v != nil && field.Kind() == reflect.String && field.Type() == jsonNumberType {
-			elem.Field(i).SetString(result.String())
-			continue
+	var value interface{}
+	if isStructureType(field.Kind().String()) {
+		value = d.parseStructureType(result, field.Type(), f)
+	} else {
+		// set field value depend on it's data type
+		value = parseDataType(result, field.Type().String())
+	}
+
+	// maybe it is a custom type, use json.unmarshal
+	if value == nil {
+		value = unmarshalGeneric(result.Raw, field)
+	}
+
+	field.Set(reflect.ValueOf(value))
+}
+
+// mergeField overlays result on the current value of field and reports
+// whether it did so. Structs and maps are merged key by key and slices are
+// appended to if asked to
+func (d *decodeState) mergeField(result gjson.Result, field reflect.Value, f fieldInfo) bool {
+	switch field.Kind() {
+	case reflect.Struct:
+		if field.Type() == timeType {
+			return false
 		}
 
-		var value interface{}
-		if isStructureType(field.Kind().String()) {
-			value = parseStructureType(result, field.Type())
-		} else {
-			// set field value depend on it's data type
-			value = parseDataType(result, field.Type().String())
+		if !gjson.Valid(result.Raw) {
+			panic(fmt.Errorf("invalid json: %v", result.Raw))
 		}
 
-		// maybe it is a custom type, use json.unmarshal
-		if value == nil {
-			value = unmarshalGeneric(result.Raw, field)
+		d.decodeStruct([]byte(result.Raw), field, f)
+		return true
+	case reflect.Map:
+		if field.IsNil() {
+			return false
 		}
 
-		if v != nil {
-			elem.Field(i).Set(reflect.ValueOf(value))
+		// values are converted as without Merge, then set key by key
+		overlay := reflect.ValueOf(d.unmarshalMap(result, field.Type(), f))
+		iter := overlay.MapRange()
+		for iter.Next() {
+			field.SetMapIndex(iter.Key(), iter.Value())
 		}
+		return true
+	case reflect.Slice:
+		if !d.opts.AppendSlices {
+			return false
+		}
+
+		value := d.unmarshalSlice(result.Array(), field.Type(), f)
+		field.Set(reflect.AppendSlice(field, reflect.ValueOf(value)))
+		return true
+	default:
+		return false
 	}
+}
 
-	return
+// decodePointer sets the pointer to a struct field from result. Merging
+// overlays result on the struct it already points to, if any
+func (d *decodeState) decodePointer(result gjson.Result, field reflect.Value, f fieldInfo) {
+	if d.opts.Merge && !field.IsNil() && d.mergeField(result, field.Elem(), f) {
+		return
+	}
+
+	ptr := reflect.New(field.Type().Elem())
+	ptr.Elem().Set(reflect.ValueOf(d.unmarshalStruct(result.Raw, field.Type().Elem(), f)))
+	field.Set(ptr)
 }
 
-func unmarshalSlice(results []gjson.Result, field reflect.Type) interface{} {
-	newSlice := reflect.MakeSlice(field, 0, 0)
+// catchPanic recovers from a panic raised while decoding and stores it in err
+func catchPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = panicError(r)
+	}
+}
 
-	for i := 0; i < len(results); i++ {
+func panicError(r interface{}) error {
+	switch x := r.(type) {
+	case string:
+		return errors.New(x)
+	case error:
+		return x
+	default:
+		return fmt.Errorf("unknown panic: %v", r)
+	}
+}
 
-		var value interface{}
-		if isStructureType(field.Elem().Kind().String()) {
-			value = parseStructureType(results[i], field.Elem())
-		} else {
-			// set field value depend on it's data type
-			value = parseDataType(results[i], field.Elem().String())
+func (d *decodeState) unmarshalSlice(results []gjson.Result, field reflect.Type, f fieldInfo) interface{} {
+	policy := d.elementPolicy(f)
+	if policy == ElementFail {
+		// every element is kept, they are decoded in place
+		newSlice := reflect.MakeSlice(field, len(results), len(results))
+		for i := range results {
+			d.decodeField(results[i], newSlice.Index(i), f.elem(i))
 		}
 
-		if value != nil {
-			newSlice = reflect.Append(newSlice, reflect.ValueOf(value))
+		return newSlice.Interface()
+	}
+
+	newSlice := reflect.MakeSlice(field, 0, len(results))
+	for i := 0; i < len(results); i++ {
+		value := reflect.New(field.Elem()).Elem()
+
+		err := d.decodeElem(results[i], value, f.elem(i))
+		if err != nil {
+			if d.opts.Skipped != nil {
+				*d.opts.Skipped = append(*d.opts.Skipped, ElementError{
+					Field: f.name(),
+					Path:  f.path(),
+					Index: i,
+					Err:   err,
+				})
+			}
+
+			if policy == ElementSkip {
+				continue
+			}
+
+			value = reflect.Zero(field.Elem())
 		}
+
+		newSlice = reflect.Append(newSlice, value)
 	}
 
 	return newSlice.Interface()
 }
 
-func unmarshalMap(raw string, field reflect.Type) interface{} {
-	m := reflect.New(reflect.MapOf(field.Key(), field.Elem())).Interface()
+// decodeElem sets a single slice element, returning any failure so the
+// element can be skipped or zeroed
+func (d *decodeState) decodeElem(result gjson.Result, value reflect.Value, f fieldInfo) (err error) {
+	// any failure inside the element, even a nested one, counts against it
+	collect := d.opts.CollectErrors
+	d.opts.CollectErrors = false
+	defer func() {
+		d.opts.CollectErrors = collect
+		if r := recover(); r != nil {
+			err = panicError(r)
+		}
+	}()
 
-	err := json.Unmarshal([]byte(raw), m)
-	if err != nil {
-		panic(err)
+	d.setField(result, value, f)
+
+	return nil
+}
+
+// elementPolicy returns the policy for the elements of the slice field f,
+// the "elements" tag option takes precedence over UnmarshalOptions
+func (d *decodeState) elementPolicy(f fieldInfo) ElementPolicy {
+	switch f.opts.get("elements") {
+	case "fail":
+		return ElementFail
+	case "skip":
+		return ElementSkip
+	case "zero":
+		return ElementZero
+	case "":
+		return d.opts.ElementErrors
+	default:
+		panic(fmt.Errorf("unknown elements policy: %s", f.opts.get("elements")))
+	}
+}
+
+func (d *decodeState) unmarshalMap(result gjson.Result, field reflect.Type, f fieldInfo) interface{} {
+	// values are left to encoding/json unless they need njson's conversions
+	if !f.opts.has("enum") && !f.opts.has("string") && registeredEnum(field.Elem()) == nil && numberParser(field.Elem()) == nil {
+		m := reflect.New(reflect.MapOf(field.Key(), field.Elem())).Interface()
+
+		err := json.Unmarshal([]byte(result.Raw), m)
+		if err != nil {
+			panic(err)
+		}
+
+		return reflect.Indirect(reflect.ValueOf(m)).Interface()
+	}
+
+	if !result.IsObject() {
+		panic(fmt.Errorf("cannot unmarshal %s %s into %v", result.Type, result.Raw, field))
+	}
+
+	m := reflect.MakeMap(field)
+	result.ForEach(func(key, value gjson.Result) bool {
+		k := mapKey(key.String(), field.Key())
+		v := reflect.New(field.Elem()).Elem()
+		d.decodeField(value, v, f.key(key.String()))
+
+		m.SetMapIndex(k, v)
+		return true
+	})
+
+	return m.Interface()
+}
+
+// mapKey converts an object key to the key type of a map the way
+// encoding/json does
+func mapKey(key string, typ reflect.Type) reflect.Value {
+	k := reflect.New(typ).Elem()
+	if _, ok := k.Addr().Interface().(encoding.TextUnmarshaler); ok || typ.Kind() == reflect.String {
+		unmarshalGeneric(strconv.Quote(key), k)
+		return k
 	}
 
-	return reflect.Indirect(reflect.ValueOf(m)).Interface()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, typ.Bits())
+		if err != nil {
+			panic(fmt.Errorf("invalid map key %q for %v", key, typ))
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(key, 10, typ.Bits())
+		if err != nil {
+			panic(fmt.Errorf("invalid map key %q for %v", key, typ))
+		}
+		k.SetUint(n)
+	default:
+		panic(fmt.Errorf("unsupported map key type %v", typ))
+	}
+
+	return k
 }
 
-func unmarshalStruct(raw string, field reflect.Type) interface{} {
-	v := reflect.New(field).Interface()
+func (d *decodeState) unmarshalStruct(raw string, field reflect.Type, f fieldInfo) interface{} {
+	if !gjson.Valid(raw) {
+		panic(fmt.Errorf("invalid json: %v", raw))
+	}
 
-	err := Unmarshal([]byte(raw), v)
-	if err != nil {
-		panic(err)
+	v := reflect.New(field).Elem()
+	if u, ok := v.Addr().Interface().(Unmarshaler); ok && d.opts.generated() {
+		if err := u.UnmarshalNJSON([]byte(raw)); err != nil {
+			panic(WrapFieldError(err, f.name(), f.path()))
+		}
+		return v.Interface()
 	}
 
-	return reflect.Indirect(reflect.ValueOf(v)).Interface()
+	d.decodeStruct([]byte(raw), v, f)
+
+	return v.Interface()
 }
 
 func unmarshalGeneric(raw string, field reflect.Value) interface{} {
@@ -142,5 +557,23 @@ func unmarshalGeneric(raw string, field reflect.Value) interface{} {
 		panic(err)
 	}
 
-	return reflect.Indirect(field).Interface()
+	return field.Interface()
+}
+
+// joinField appends the struct field name to the Go path of its parent
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// joinPath appends path to the njson path of its parent
+func joinPath(parent, path string) string {
+	if parent == "" {
+		return path
+	}
+
+	return parent + "." + path
 }
@@ -612,7 +612,7 @@ func TestUnmarshalComplex(t *testing.T) {
 		Time1                  time.Time `njson:"time_1"`
 		Time2                  time.Time `njson:"time_2"`
 		Time3                  time.Time `njson:"time_3"`
-		//FavoriteMovie    string   `njson:"fav.movie"`
+		FavoriteMovie          string    `json:"fav.movie"`
 	}
 
 	actual := User{}
@@ -637,6 +637,7 @@ func TestUnmarshalComplex(t *testing.T) {
 		Time1:                  t1,
 		Time2:                  t2,
 		Time3:                  t3,
+		FavoriteMovie:          "Deer Hunter",
 	}
 
 	diff := cmp.Diff(expected, actual)
@@ -778,33 +779,365 @@ func TestUnmarshalJson(t *testing.T) {
 		}
 	})
 
-	t.Run("fail", func(t *testing.T) {
+	t.Run("dotted key", func(t *testing.T) {
 		json := `
 		{
 			"name": {"first": "Mohamed", "last": "Shapan"},
-			"age": 26,
-			"friends": [
-				{"first": "Asma", "age": 26},
-				{"first": "Ahmed", "age": 25},
-				{"first": "Mahmoud", "age": 30}
-			]
+			"name.first": "literal",
+			"k8s.io/name": "njson",
+			"age": 26
 		}`
 
-		type Name struct {
-			First string `njson:"first"`
-			Last  string `njson:"last"`
-		}
-
 		type User struct {
-			Name    string `json:"name.first"`
-			Age     int    `json:"age"`
-			Friends []Name `json:"friends"`
+			Name string `json:"name.first"`
+			App  string `json:"k8s.io/name"`
+			Age  int    `json:"age"`
 		}
 
 		actual := User{}
 
+		if err := Unmarshal([]byte(json), &actual); err != nil {
+			t.Error(err)
+		}
+
+		expected := User{
+			Name: "literal",
+			App:  "njson",
+			Age:  26,
+		}
+
+		diff := cmp.Diff(expected, actual)
+		if diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestUnmarshalSliceElementPolicy(t *testing.T) {
+	json := `
+	{
+		"friends": [
+			{"first": "Asma", "meta": {"a": 1}},
+			{"first": "Ahmed", "meta": "broken"},
+			{"first": "Mahmoud", "meta": {"b": 2}}
+		]
+	}`
+
+	type Friend struct {
+		First string         `njson:"first"`
+		Meta  map[string]int `njson:"meta"`
+	}
+
+	type Policy struct {
+		Friends []Friend `njson:"friends"`
+		Zeroed  []Friend `njson:"friends,elements=zero"`
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		type Fail struct {
+			Friends []Friend `njson:"friends"`
+		}
+
+		actual := Fail{}
 		if err := Unmarshal([]byte(json), &actual); err == nil {
 			t.Error("error should not be nil")
 		}
 	})
+
+	t.Run("skip and zero", func(t *testing.T) {
+		var skipped []ElementError
+		actual := Policy{}
+
+		err := UnmarshalOptions{ElementErrors: ElementSkip, Skipped: &skipped}.Unmarshal([]byte(json), &actual)
+		if err != nil {
+			t.Error(err)
+		}
+
+		expected := Policy{
+			Friends: []Friend{
+				{First: "Asma", Meta: map[string]int{"a": 1}},
+				{First: "Mahmoud", Meta: map[string]int{"b": 2}},
+			},
+			// the element keeps its index with a zero value
+			Zeroed: []Friend{
+				{First: "Asma", Meta: map[string]int{"a": 1}},
+				{},
+				{First: "Mahmoud", Meta: map[string]int{"b": 2}},
+			},
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+
+		var indices []string
+		for _, e := range skipped {
+			indices = append(indices, fmt.Sprintf("%s[%d]", e.Field, e.Index))
+		}
+
+		if diff := cmp.Diff([]string{"Friends[1]", "Zeroed[1]"}, indices); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestUnmarshalSliceOfPointers(t *testing.T) {
+	json := `{"friends": [{"first": "Asma"}, {"first": "Ahmed"}], "any": [1, "two", true]}`
+
+	type Name struct {
+		First string `json:"first"`
+	}
+
+	type Slices struct {
+		Friends []*Name       `njson:"friends"`
+		Any     []interface{} `njson:"any"`
+	}
+
+	actual := Slices{}
+
+	err := Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := Slices{
+		Friends: []*Name{{First: "Asma"}, {First: "Ahmed"}},
+		Any:     []interface{}{float64(1), "two", true},
+	}
+
+	diff := cmp.Diff(expected, actual)
+	if diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalPresent(t *testing.T) {
+	json := `{"name": {"first": "Mohamed"}, "age": 0, "deleted_at": null}`
+
+	type Name struct {
+		First string `njson:"first"`
+		Last  string `njson:"last"`
+	}
+
+	type User struct {
+		Name      Name              `njson:"name"`
+		Age       int               `njson:"age"`
+		Email     string            `njson:"contact.email"`
+		DeletedAt *time.Time        `njson:"deleted_at"`
+		Address   Name              `njson:"address"`
+		Labels    map[string]string `njson:"labels"`
+	}
+
+	fields := map[string]bool{}
+	paths := map[string]bool{}
+	actual := User{}
+
+	err := UnmarshalOptions{PresentFields: fields, PresentPaths: paths}.Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedFields := map[string]bool{
+		"Name":       true,
+		"Name.First": true,
+		"Name.Last":  false,
+		"Age":        true,
+		"Email":      false,
+		"DeletedAt":  true,
+		"Address":    false,
+		"Labels":     false,
+	}
+
+	if diff := cmp.Diff(expectedFields, fields); diff != "" {
+		t.Error(diff)
+	}
+
+	expectedPaths := map[string]bool{
+		"name":          true,
+		"name.first":    true,
+		"name.last":     false,
+		"age":           true,
+		"contact.email": false,
+		"deleted_at":    true,
+		"address":       false,
+		"labels":        false,
+	}
+
+	if diff := cmp.Diff(expectedPaths, paths); diff != "" {
+		t.Error(diff)
+	}
+
+	if diff := cmp.Diff(User{Name: Name{First: "Mohamed"}}, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalPresentCollision(t *testing.T) {
+	type User struct {
+		ID     int `njson:"user.id"`
+		UserID int `njson:"ID"`
+	}
+
+	fields := map[string]bool{}
+	paths := map[string]bool{}
+
+	err := UnmarshalOptions{PresentFields: fields, PresentPaths: paths}.Unmarshal([]byte(`{"user": {"id": 1}}`), &User{})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(map[string]bool{"ID": true, "UserID": false}, fields); diff != "" {
+		t.Error(diff)
+	}
+
+	if diff := cmp.Diff(map[string]bool{"user.id": true, "ID": false}, paths); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalMerge(t *testing.T) {
+	type Server struct {
+		Host string `njson:"host"`
+		Port int    `njson:"port"`
+	}
+
+	type Config struct {
+		Name    string            `njson:"name"`
+		Server  Server            `njson:"server"`
+		Labels  map[string]string `njson:"labels"`
+		Plugins []string          `njson:"plugins"`
+		Debug   bool              `njson:"debug"`
+	}
+
+	base := `{"name": "base", "server": {"host": "localhost", "port": 80}, "labels": {"env": "dev"}, "plugins": ["auth"]}`
+	overlay := `{"server": {"port": 8080}, "labels": {"team": "core"}, "plugins": ["cache"], "debug": true}`
+
+	actual := Config{}
+
+	if err := Unmarshal([]byte(base), &actual); err != nil {
+		t.Error(err)
+	}
+
+	if err := (UnmarshalOptions{Merge: true, AppendSlices: true}).Unmarshal([]byte(overlay), &actual); err != nil {
+		t.Error(err)
+	}
+
+	expected := Config{
+		Name:    "base",
+		Server:  Server{Host: "localhost", Port: 8080},
+		Labels:  map[string]string{"env": "dev", "team": "core"},
+		Plugins: []string{"auth", "cache"},
+		Debug:   true,
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := (UnmarshalOptions{Merge: true}).Unmarshal([]byte(`{"plugins": ["metrics"]}`), &actual); err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff([]string{"metrics"}, actual.Plugins); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalMergeConversions(t *testing.T) {
+	type Level int
+
+	type Config struct {
+		Name   string           `njson:"name"`
+		Limit  json.Number      `njson:"limit"`
+		Ports  map[string]int   `njson:"ports,string"`
+		Levels map[string]Level `njson:"levels,enum=low|high"`
+	}
+
+	actual := Config{
+		Limit:  "5",
+		Ports:  map[string]int{"http": 80},
+		Levels: map[string]Level{"api": 0},
+	}
+
+	overlay := `{"name": "y", "ports": {"https": "443"}, "levels": {"db": "high"}}`
+	if err := (UnmarshalOptions{Merge: true}).Unmarshal([]byte(overlay), &actual); err != nil {
+		t.Error(err)
+	}
+
+	expected := Config{
+		Name:   "y",
+		Limit:  "5",
+		Ports:  map[string]int{"http": 80, "https": 443},
+		Levels: map[string]Level{"api": 0, "db": 1},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalStringOption(t *testing.T) {
+	json := `{"amount": "12.50", "active": "true", "count": "7", "ids": ["1", "2"], "quoted": "\"hi\""}`
+
+	type Payment struct {
+		Amount float64 `njson:"amount,string"`
+		Active bool    `njson:"active,string"`
+		Count  uint16  `njson:"count,string"`
+		IDs    []int64 `njson:"ids,string"`
+		Quoted string  `njson:"quoted,string"`
+	}
+
+	actual := Payment{}
+
+	err := UnmarshalOptions{Strict: true}.Unmarshal([]byte(json), &actual)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := Payment{Amount: 12.5, Active: true, Count: 7, IDs: []int64{1, 2}, Quoted: "hi"}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	type Invalid struct {
+		Count int `njson:"amount,string"`
+	}
+
+	if err := Unmarshal([]byte(json), &Invalid{}); err == nil {
+		t.Error("error should not be nil")
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	tests := []struct {
+		json   string
+		strict bool
+	}{
+		{json: `{"amount": 12, "active": true}`, strict: true},
+		{json: `{"amount": "12", "active": true}`},
+		{json: `{"amount": 12.5, "active": true}`},
+		{json: `{"amount": 12, "active": "true"}`},
+		{json: `{"amount": 300, "active": true}`},
+	}
+
+	type Account struct {
+		Amount int8 `njson:"amount"`
+		Active bool `njson:"active"`
+	}
+
+	for _, tt := range tests {
+		if err := Unmarshal([]byte(tt.json), &Account{}); err != nil {
+			t.Errorf("%s: error should be nil without Strict: %v", tt.json, err)
+		}
+
+		err := UnmarshalOptions{Strict: true}.Unmarshal([]byte(tt.json), &Account{})
+		if tt.strict && err != nil {
+			t.Errorf("%s: error should be nil: %v", tt.json, err)
+		}
+
+		if !tt.strict && err == nil {
+			t.Errorf("%s: error should not be nil", tt.json)
+		}
+	}
 }
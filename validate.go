@@ -0,0 +1,193 @@
+package njson
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// rules lists the tag options that validate a decoded field, in the order
+// they are checked
+var rules = []string{"required", "nonempty", "len", "min", "max", "oneof", "email", "regex"}
+
+var regexps sync.Map // map[string]*regexp.Regexp
+
+// RuleError reports a field value that breaks one of the validation rules
+// in its tag, such as `njson:"age,min=0,max=150"`
+type RuleError struct {
+	Rule  string
+	Param string
+	Value interface{}
+}
+
+func (e *RuleError) Error() string {
+	rule := e.Rule
+	if e.Param != "" {
+		rule += "=" + e.Param
+	}
+
+	return fmt.Sprintf("value %v breaks rule %s", e.Value, rule)
+}
+
+// validateField checks the value decoded into field against the rules in its
+// tag options. The rules apply to the field itself, not its elements, and
+// all but required are skipped when the path is missing:
+//
+//	required  the path must exist
+//	nonempty  the value must not be the zero value or empty
+//	len=n     strings, slices and maps must have exactly n elements
+//	min=n     numbers must be at least n, strings, slices and maps must have
+//	          at least n elements
+//	max=n     like min, but at most n
+//	oneof=a|b the value must be one of the listed ones
+//	email     the value must be a plain e-mail address
+//	regex=re  strings must match the regular expression re
+func (d *decodeState) validateField(result gjson.Result, field reflect.Value, f fieldInfo) {
+	for _, rule := range rules {
+		if !f.opts.has(rule) {
+			continue
+		}
+
+		if err := checkRule(rule, f.opts.get(rule), result, field); err != nil {
+			d.fail(f, err)
+		}
+	}
+}
+
+func checkRule(rule, param string, result gjson.Result, field reflect.Value) error {
+	if rule == "required" {
+		if !result.Exists() {
+			return &RuleError{Rule: rule, Value: "missing"}
+		}
+		return nil
+	}
+
+	// a missing path is only an error for required fields
+	if !result.Exists() {
+		return nil
+	}
+
+	v := field
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if rule == "nonempty" {
+				return &RuleError{Rule: rule, Value: "nil"}
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var ok bool
+	switch rule {
+	case "nonempty":
+		ok = !v.IsZero() && !(hasLen(v) && v.Len() == 0)
+	case "len":
+		n, err := strconv.Atoi(param)
+		if err != nil || !hasLen(v) {
+			return fmt.Errorf("invalid rule len=%s for %v", param, field.Type())
+		}
+		ok = length(v) == n
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rule %s=%s for %v", rule, param, field.Type())
+		}
+
+		n, err := magnitude(v)
+		if err != nil {
+			return fmt.Errorf("invalid rule %s=%s for %v", rule, param, field.Type())
+		}
+
+		if rule == "min" {
+			ok = n >= limit
+		} else {
+			ok = n <= limit
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, allowed := range strings.Split(param, "|") {
+			if s == allowed {
+				ok = true
+				break
+			}
+		}
+	case "email":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("invalid rule email for %v", field.Type())
+		}
+		addr, err := mail.ParseAddress(v.String())
+		ok = err == nil && addr.Address == v.String()
+	case "regex":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("invalid rule regex for %v", field.Type())
+		}
+		re, err := compileRegexp(param)
+		if err != nil {
+			return err
+		}
+		ok = re.MatchString(v.String())
+	}
+
+	if !ok {
+		return &RuleError{Rule: rule, Param: param, Value: v.Interface()}
+	}
+
+	return nil
+}
+
+func hasLen(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// length counts the characters of a string or the elements of anything else
+func length(v reflect.Value) int {
+	if v.Kind() == reflect.String {
+		return utf8.RuneCountInString(v.String())
+	}
+
+	return v.Len()
+}
+
+// magnitude is what min and max compare: the value of a number or the
+// length of a string, slice or map
+func magnitude(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(length(v)), nil
+	default:
+		return 0, fmt.Errorf("can't compare %v", v.Type())
+	}
+}
+
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule regex=%s: %w", expr, err)
+	}
+
+	regexps.Store(expr, re)
+	return re, nil
+}
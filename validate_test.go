@@ -0,0 +1,98 @@
+package njson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshalValidate(t *testing.T) {
+	type User struct {
+		Name   string   `njson:"name,nonempty,max=10"`
+		Age    int      `njson:"age,min=0,max=150"`
+		Email  string   `njson:"contact.email,email"`
+		Code   string   `njson:"code,len=3,regex=^[A-Z]{2,3}$"`
+		Role   string   `njson:"role,oneof=user|admin"`
+		Tags   []string `njson:"tags,min=1"`
+		Parent *User    `njson:"parent"`
+		ID     int      `njson:"id,required"`
+	}
+
+	valid := `{"id": 1, "name": "Mohamed", "age": 26, "contact": {"email": "m@example.com"}, "code": "ABC", "role": "admin", "tags": ["a"]}`
+
+	if err := Unmarshal([]byte(valid), &User{}); err != nil {
+		t.Error(err)
+	}
+
+	invalid := `{"name": "", "age": 200, "contact": {"email": "Mohamed <m@example.com>"}, "code": "abc", "role": "root", "tags": []}`
+
+	err := UnmarshalOptions{CollectErrors: true}.Unmarshal([]byte(invalid), &User{})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	var actual []string
+	for _, fe := range errs {
+		var re *RuleError
+		if !errors.As(fe, &re) {
+			t.Errorf("error should be a *RuleError, got %v", fe)
+			continue
+		}
+		actual = append(actual, fe.Path+" "+re.Rule)
+	}
+
+	expected := []string{
+		"name nonempty",
+		"age max",
+		"contact.email email",
+		"code regex",
+		"role oneof",
+		"tags min",
+		"id required",
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUnmarshalValidateMissing(t *testing.T) {
+	type User struct {
+		Email string   `njson:"email,email"`
+		Login string   `njson:"login,regex=^[a-z]+$"`
+		Age   int      `njson:"age,min=18"`
+		Tags  []string `njson:"tags,min=1,nonempty"`
+		ID    int      `njson:"id,required,min=1"`
+	}
+
+	if err := Unmarshal([]byte(`{"id": 1}`), &User{}); err != nil {
+		t.Error(err)
+	}
+
+	err := Unmarshal([]byte(`{}`), &User{})
+
+	var re *RuleError
+	if !errors.As(err, &re) || re.Rule != "required" {
+		t.Errorf("missing id should break the required rule, got %v", err)
+	}
+}
+
+func TestUnmarshalValidateFailedField(t *testing.T) {
+	type Tag struct {
+		Count int `njson:"t,min=3"`
+	}
+
+	err := UnmarshalOptions{Strict: true, CollectErrors: true}.Unmarshal([]byte(`{"t": "abc"}`), &Tag{})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error should be Errors, got %v", err)
+	}
+
+	if len(errs) != 1 {
+		t.Errorf("a field that fails to decode should only report that, got %v", err)
+	}
+}